@@ -0,0 +1,85 @@
+package main
+
+import (
+	"ProtectedArea/internal/config"
+	"ProtectedArea/internal/model"
+	"ProtectedArea/internal/service"
+	"ProtectedArea/internal/store"
+	"ProtectedArea/pkg/export"
+	"encoding/json"
+	"flag"
+	"io"
+	"log"
+	"os"
+)
+
+// runCheckImages 命令行检查图片目录: ProtectedArea check-images [-year 2024] [-pc 202401] [-deep] [-format json|csv] [-view issues|missing] [-out report.csv]
+// 报告输出到标准输出 (或 -out 指定的文件)，发现任何问题时退出码为 1
+func runCheckImages(args []string) {
+	fs := flag.NewFlagSet("check-images", flag.ExitOnError)
+	configPath := fs.String("config", "", "配置文件路径 (默认读取 PA_CONFIG 或 "+config.DefaultPath+")")
+	format := fs.String("format", "json", "输出格式: json 或 csv")
+	outPath := fs.String("out", "", "输出文件 (默认输出到标准输出)")
+	var req model.ImageCheckRequest
+	fs.StringVar(&req.Year, "year", "", "只检查该年份图斑的缺图情况")
+	fs.StringVar(&req.PC, "pc", "", "只检查该批次图斑的缺图情况")
+	fs.BoolVar(&req.Deep, "deep", false, "完整解码每张图片 (较慢)，默认只读取文件头")
+	fs.StringVar(&req.View, "view", model.ImageCheckViewIssues, "csv 输出的内容: issues (问题明细) 或 missing (缺图统计)")
+	_ = fs.Parse(args)
+
+	if *format != "json" && *format != export.FormatCSV {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	cfg, db := mustSetup(*configPath)
+	imageService := service.NewImageService(store.NewImageStore(db), store.NewNatureStore(db), cfg.Image.Root, cfg.Image.ThumbnailDir())
+
+	report, err := imageService.CheckImages(req)
+	if err != nil {
+		log.Fatal("检查图片失败: ", err)
+	}
+
+	out := io.Writer(os.Stdout)
+	var file *os.File
+	if *outPath != "" {
+		if file, err = os.Create(*outPath); err != nil {
+			log.Fatal("创建输出文件失败: ", err)
+		}
+		out = file
+	}
+	err = writeCheckReport(out, *format, req.View, report)
+	if file != nil {
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		log.Fatal("输出检查报告失败: ", err)
+	}
+
+	sum := report.Summary
+	log.Printf("检查完成: 图斑 %d 个 (有图 %d 个), 图片 %d 张; 缺图 %d, 孤立文件 %d, 损坏 %d, 重复 %d (%s)",
+		sum.Spots, sum.WithImages, sum.Files, sum.Missing, sum.Orphan, sum.Corrupt, sum.Duplicate, sum.Duration)
+	if len(report.Issues) > 0 {
+		os.Exit(1)
+	}
+}
+
+// writeCheckReport 按 json 或 csv 输出检查报告
+func writeCheckReport(out io.Writer, format, view string, report *model.ImageCheckReport) error {
+	if format == "json" {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+
+	w, err := export.NewWriter(format, out)
+	if err != nil {
+		return err
+	}
+	if err := service.WriteImageCheckReport(report, view, w); err != nil {
+		return err
+	}
+	return w.Close()
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"ProtectedArea/internal/config"
+	"ProtectedArea/internal/model"
+	"ProtectedArea/internal/service"
+	"ProtectedArea/internal/store"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+)
+
+// runImport 命令行导入图斑: ProtectedArea import -file data.xlsx [-year 2024] [-pc 202401] [-dry-run]
+// 导入报告以 JSON 输出到标准输出，存在失败行时退出码为 1
+func runImport(args []string) {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	configPath := fs.String("config", "", "配置文件路径 (默认读取 PA_CONFIG 或 "+config.DefaultPath+")")
+	filePath := fs.String("file", "", "要导入的 CSV 或 XLSX 文件 (必填)")
+	var opts model.ImportOptions
+	fs.StringVar(&opts.Year, "year", "", "文件中没有 year 列时使用的年份")
+	fs.StringVar(&opts.PC, "pc", "", "文件中没有 PC 列时使用的批次")
+	fs.BoolVar(&opts.DryRun, "dry-run", false, "只校验不入库")
+	_ = fs.Parse(args)
+
+	if *filePath == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	_, db := mustSetup(*configPath)
+	importService := service.NewImportService(store.NewNatureStore(db))
+
+	f, err := os.Open(*filePath)
+	if err != nil {
+		log.Fatal("打开文件失败: ", err)
+	}
+	defer f.Close()
+
+	result, err := importService.ImportSpots(f.Name(), f, opts)
+	if err != nil {
+		log.Fatal("导入失败: ", err)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(result)
+
+	log.Printf("导入完成: 共 %d 行, 成功 %d 行, 失败 %d 行", result.TotalRows, result.Succeeded, result.Failed)
+	if result.Failed > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,305 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultPath 默认配置文件路径 (相对于工作目录)
+const DefaultPath = "config/config.yaml"
+
+// envPrefix 环境变量前缀，例如 PA_DB_HOST
+const envPrefix = "PA_"
+
+// Config 服务的全部配置
+type Config struct {
+	Server   ServerConfig   `yaml:"server"`
+	Database DatabaseConfig `yaml:"database"`
+	Image    ImageConfig    `yaml:"image"`
+	Auth     AuthConfig     `yaml:"auth"`
+}
+
+// ServerConfig HTTP 服务相关配置
+type ServerConfig struct {
+	Port int    `yaml:"port"` // 监听端口
+	Mode string `yaml:"mode"` // gin 运行模式: debug, release, test
+}
+
+// DatabaseConfig MySQL 连接及连接池配置
+type DatabaseConfig struct {
+	// DSN 如果直接配置了完整 DSN，则忽略下面的分项配置
+	DSN      string `yaml:"dsn"`
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	User     string `yaml:"user"`
+	Password string `yaml:"password"`
+	Name     string `yaml:"name"`   // 数据库名
+	Params   string `yaml:"params"` // 连接参数，例如 charset=utf8mb4&parseTime=True&loc=Local
+
+	MaxOpenConns    int           `yaml:"max_open_conns"`
+	MaxIdleConns    int           `yaml:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime"` // 例如 1h, 30m
+}
+
+// ImageConfig 图斑图片相关配置
+type ImageConfig struct {
+	Root string `yaml:"root"` // 图片存放的根目录
+	// ScanOnStart 启动时在后台扫描图片目录并更新图片索引 (也可以调用 POST /api/images/rescan)
+	ScanOnStart bool `yaml:"scan_on_start"`
+	// CacheDir 缩略图缓存目录，为空时使用 <root>/.thumbnails (扫描图片目录时会跳过以 . 开头的目录)
+	CacheDir string `yaml:"cache_dir"`
+}
+
+// AuthConfig 认证相关配置
+type AuthConfig struct {
+	// Enabled 为 false 时所有接口匿名可访问且不做数据范围限制，只应在本地开发时关闭
+	Enabled bool `yaml:"enabled"`
+	// JWTSecret JWT 签名密钥，至少 16 个字符，只能通过 PA_AUTH_JWT_SECRET 设置 (配置文件会提交到代码库)
+	JWTSecret string        `yaml:"jwt_secret"`
+	TokenTTL  time.Duration `yaml:"token_ttl"` // 登录令牌有效期，例如 12h
+
+	// 首次启动时如果用户表为空，自动创建该管理员账号
+	// 密码只能通过 PA_AUTH_ADMIN_PASSWORD 设置，为空时不创建
+	AdminUsername string `yaml:"admin_username"`
+	AdminPassword string `yaml:"admin_password"`
+
+	// secretFromEnv / passwordFromEnv 记录密钥和密码是否来自环境变量，由 applyEnv 设置
+	secretFromEnv   bool
+	passwordFromEnv bool
+}
+
+// Default 返回开发环境下的默认配置
+func Default() Config {
+	return Config{
+		Server: ServerConfig{
+			Port: 9094,
+			Mode: "debug",
+		},
+		Database: DatabaseConfig{
+			Host:            "127.0.0.1",
+			Port:            3306,
+			User:            "root",
+			Name:            "protected_area",
+			Params:          "charset=utf8mb4&parseTime=True&loc=Local",
+			MaxOpenConns:    20,
+			MaxIdleConns:    10,
+			ConnMaxLifetime: time.Hour,
+		},
+		Image: ImageConfig{
+			Root:        "./image/",
+			ScanOnStart: true,
+		},
+		Auth: AuthConfig{
+			Enabled:       true,
+			TokenTTL:      12 * time.Hour,
+			AdminUsername: "admin",
+		},
+	}
+}
+
+// Load 加载配置: 默认值 -> YAML 文件 -> 环境变量，最后统一校验
+// path 为空时依次尝试环境变量 PA_CONFIG 和 DefaultPath；
+// 未显式指定且默认文件不存在时，只使用默认值和环境变量
+func Load(path string) (*Config, error) {
+	cfg := Default()
+
+	explicit := path != ""
+	if !explicit {
+		path = os.Getenv(envPrefix + "CONFIG")
+		explicit = path != ""
+	}
+	if path == "" {
+		path = DefaultPath
+	}
+
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if err := yaml.Unmarshal(data, &cfg); err != nil {
+			return nil, fmt.Errorf("解析配置文件 %s 失败: %w", path, err)
+		}
+	case errors.Is(err, os.ErrNotExist) && !explicit:
+		// 默认路径下没有配置文件，继续使用默认值
+	default:
+		return nil, fmt.Errorf("读取配置文件 %s 失败: %w", path, err)
+	}
+
+	if err := cfg.applyEnv(); err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// applyEnv 使用环境变量覆盖配置项
+func (c *Config) applyEnv() error {
+	var errs []error
+
+	setString := func(key string, dst *string) {
+		if v, ok := os.LookupEnv(envPrefix + key); ok {
+			*dst = v
+		}
+	}
+	setInt := func(key string, dst *int) {
+		if v, ok := os.LookupEnv(envPrefix + key); ok {
+			n, err := strconv.Atoi(strings.TrimSpace(v))
+			if err != nil {
+				errs = append(errs, fmt.Errorf("环境变量 %s%s 不是合法整数: %q", envPrefix, key, v))
+				return
+			}
+			*dst = n
+		}
+	}
+	setBool := func(key string, dst *bool) {
+		if v, ok := os.LookupEnv(envPrefix + key); ok {
+			b, err := strconv.ParseBool(strings.TrimSpace(v))
+			if err != nil {
+				errs = append(errs, fmt.Errorf("环境变量 %s%s 不是合法布尔值: %q", envPrefix, key, v))
+				return
+			}
+			*dst = b
+		}
+	}
+	setDuration := func(key string, dst *time.Duration) {
+		if v, ok := os.LookupEnv(envPrefix + key); ok {
+			d, err := time.ParseDuration(strings.TrimSpace(v))
+			if err != nil {
+				errs = append(errs, fmt.Errorf("环境变量 %s%s 不是合法时长: %q", envPrefix, key, v))
+				return
+			}
+			*dst = d
+		}
+	}
+
+	setInt("SERVER_PORT", &c.Server.Port)
+	setString("GIN_MODE", &c.Server.Mode)
+
+	setString("DB_DSN", &c.Database.DSN)
+	setString("DB_HOST", &c.Database.Host)
+	setInt("DB_PORT", &c.Database.Port)
+	setString("DB_USER", &c.Database.User)
+	setString("DB_PASSWORD", &c.Database.Password)
+	setString("DB_NAME", &c.Database.Name)
+	setString("DB_PARAMS", &c.Database.Params)
+	setInt("DB_MAX_OPEN_CONNS", &c.Database.MaxOpenConns)
+	setInt("DB_MAX_IDLE_CONNS", &c.Database.MaxIdleConns)
+	setDuration("DB_CONN_MAX_LIFETIME", &c.Database.ConnMaxLifetime)
+
+	setString("IMAGE_ROOT", &c.Image.Root)
+	setBool("IMAGE_SCAN_ON_START", &c.Image.ScanOnStart)
+	setString("IMAGE_CACHE_DIR", &c.Image.CacheDir)
+
+	setBool("AUTH_ENABLED", &c.Auth.Enabled)
+	setString("AUTH_JWT_SECRET", &c.Auth.JWTSecret)
+	setDuration("AUTH_TOKEN_TTL", &c.Auth.TokenTTL)
+	setString("AUTH_ADMIN_USERNAME", &c.Auth.AdminUsername)
+	setString("AUTH_ADMIN_PASSWORD", &c.Auth.AdminPassword)
+	_, c.Auth.secretFromEnv = os.LookupEnv(envPrefix + "AUTH_JWT_SECRET")
+	_, c.Auth.passwordFromEnv = os.LookupEnv(envPrefix + "AUTH_ADMIN_PASSWORD")
+
+	return errors.Join(errs...)
+}
+
+// Validate 校验配置是否合法，一次性返回所有错误，方便排查
+func (c *Config) Validate() error {
+	var errs []error
+
+	if c.Server.Port <= 0 || c.Server.Port > 65535 {
+		errs = append(errs, fmt.Errorf("server.port 必须在 1-65535 之间, 当前为 %d", c.Server.Port))
+	}
+	switch c.Server.Mode {
+	case "debug", "release", "test":
+	default:
+		errs = append(errs, fmt.Errorf("server.mode 只能是 debug/release/test, 当前为 %q", c.Server.Mode))
+	}
+
+	db := c.Database
+	if db.DSN == "" {
+		if db.Host == "" {
+			errs = append(errs, errors.New("database.host 不能为空"))
+		}
+		if db.Port <= 0 || db.Port > 65535 {
+			errs = append(errs, fmt.Errorf("database.port 必须在 1-65535 之间, 当前为 %d", db.Port))
+		}
+		if db.User == "" {
+			errs = append(errs, errors.New("database.user 不能为空"))
+		}
+		if db.Name == "" {
+			errs = append(errs, errors.New("database.name 不能为空"))
+		}
+	}
+	if db.MaxOpenConns < 0 {
+		errs = append(errs, errors.New("database.max_open_conns 不能为负数"))
+	}
+	if db.MaxIdleConns < 0 {
+		errs = append(errs, errors.New("database.max_idle_conns 不能为负数"))
+	}
+	if db.MaxOpenConns > 0 && db.MaxIdleConns > db.MaxOpenConns {
+		errs = append(errs, fmt.Errorf("database.max_idle_conns(%d) 不能大于 max_open_conns(%d)", db.MaxIdleConns, db.MaxOpenConns))
+	}
+	if db.ConnMaxLifetime < 0 {
+		errs = append(errs, errors.New("database.conn_max_lifetime 不能为负数"))
+	}
+
+	if strings.TrimSpace(c.Image.Root) == "" {
+		errs = append(errs, errors.New("image.root 不能为空"))
+	}
+
+	// 密钥和初始密码不允许写在配置文件中
+	if c.Auth.JWTSecret != "" && !c.Auth.secretFromEnv {
+		errs = append(errs, errors.New("auth.jwt_secret 不能写在配置文件中，请通过 PA_AUTH_JWT_SECRET 设置"))
+	}
+	if c.Auth.AdminPassword != "" && !c.Auth.passwordFromEnv {
+		errs = append(errs, errors.New("auth.admin_password 不能写在配置文件中，请通过 PA_AUTH_ADMIN_PASSWORD 设置"))
+	}
+	if c.Auth.Enabled {
+		switch {
+		case !c.Auth.secretFromEnv:
+			errs = append(errs, errors.New("认证已开启，必须通过 PA_AUTH_JWT_SECRET 设置 JWT 签名密钥"))
+		case len(c.Auth.JWTSecret) < 16:
+			errs = append(errs, errors.New("PA_AUTH_JWT_SECRET 至少需要 16 个字符"))
+		}
+		if c.Auth.TokenTTL <= 0 {
+			errs = append(errs, errors.New("auth.token_ttl 必须大于 0"))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("配置校验失败:\n%w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// GetDSN 返回 MySQL 连接串
+func (d DatabaseConfig) GetDSN() string {
+	if d.DSN != "" {
+		return d.DSN
+	}
+	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s", d.User, d.Password, d.Host, d.Port, d.Name)
+	if d.Params != "" {
+		dsn += "?" + d.Params
+	}
+	return dsn
+}
+
+// Addr 返回 HTTP 监听地址，例如 ":9094"
+func (s ServerConfig) Addr() string {
+	return fmt.Sprintf(":%d", s.Port)
+}
+
+// ThumbnailDir 返回缩略图缓存目录
+func (i ImageConfig) ThumbnailDir() string {
+	if i.CacheDir != "" {
+		return i.CacheDir
+	}
+	return filepath.Join(i.Root, ".thumbnails")
+}
@@ -0,0 +1,83 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadAuthSecrets(t *testing.T) {
+	const secret = "0123456789abcdef"
+
+	tests := []struct {
+		name    string
+		yaml    string
+		env     map[string]string
+		wantErr string // 为空表示应加载成功
+	}{
+		{
+			name: "密钥来自环境变量",
+			env:  map[string]string{"PA_AUTH_JWT_SECRET": secret, "PA_AUTH_ADMIN_PASSWORD": "admin-pass"},
+		},
+		{
+			name: "关闭认证时不需要密钥",
+			env:  map[string]string{"PA_AUTH_ENABLED": "false"},
+		},
+		{
+			name:    "开启认证但未设置密钥",
+			wantErr: "必须通过 PA_AUTH_JWT_SECRET",
+		},
+		{
+			name:    "密钥过短",
+			env:     map[string]string{"PA_AUTH_JWT_SECRET": "short"},
+			wantErr: "至少需要 16 个字符",
+		},
+		{
+			name:    "密钥写在配置文件中",
+			yaml:    "auth:\n  jwt_secret: " + secret + "\n",
+			wantErr: "auth.jwt_secret 不能写在配置文件中",
+		},
+		{
+			name:    "初始密码写在配置文件中",
+			yaml:    "auth:\n  admin_password: admin-pass\n",
+			env:     map[string]string{"PA_AUTH_JWT_SECRET": secret},
+			wantErr: "auth.admin_password 不能写在配置文件中",
+		},
+		{
+			name: "环境变量覆盖配置文件中的空值",
+			yaml: "auth:\n  jwt_secret: \"\"\n  admin_password: \"\"\n",
+			env:  map[string]string{"PA_AUTH_JWT_SECRET": secret},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range []string{"PA_AUTH_ENABLED", "PA_AUTH_JWT_SECRET", "PA_AUTH_ADMIN_PASSWORD"} {
+				t.Setenv(key, "")
+				os.Unsetenv(key)
+			}
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(path, []byte(tt.yaml), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			cfg, err := Load(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			if v, ok := tt.env["PA_AUTH_JWT_SECRET"]; ok && cfg.Auth.JWTSecret != v {
+				t.Errorf("jwt secret = %q, want %q", cfg.Auth.JWTSecret, v)
+			}
+		})
+	}
+}
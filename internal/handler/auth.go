@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"ProtectedArea/internal/middleware"
+	"ProtectedArea/internal/model"
+	"ProtectedArea/internal/service"
+	"ProtectedArea/pkg/errcode"
+	"ProtectedArea/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuthHandler struct {
+	srv service.AuthService
+}
+
+func NewAuthHandler(srv service.AuthService) *AuthHandler {
+	return &AuthHandler{srv: srv}
+}
+
+// Login 登录: POST /api/auth/login {"username": "...", "password": "..."}
+func (h *AuthHandler) Login(c *gin.Context) {
+	var req model.LoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.InvalidParams(c, err)
+		return
+	}
+
+	token, expiresAt, user, err := h.srv.Login(req)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, gin.H{
+		"token":      token,
+		"token_type": "Bearer",
+		"expires_at": expiresAt,
+		"user":       user,
+	})
+}
+
+// Me 当前登录用户: GET /api/auth/me
+func (h *AuthHandler) Me(c *gin.Context) {
+	user := middleware.CurrentUser(c)
+	if user == nil {
+		response.Error(c, errcode.Unauthorized.WithMessage("认证未开启或未登录"))
+		return
+	}
+	response.Success(c, user)
+}
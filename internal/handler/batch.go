@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"ProtectedArea/internal/model"
+	"ProtectedArea/internal/service"
+	"ProtectedArea/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BatchHandler 批次登记表
+type BatchHandler struct {
+	srv service.BatchService
+}
+
+func NewBatchHandler(srv service.BatchService) *BatchHandler {
+	return &BatchHandler{srv: srv}
+}
+
+// List 批次列表: GET /api/batches?year=2024
+func (h *BatchHandler) List(c *gin.Context) {
+	var req model.BatchQueryRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.InvalidParams(c, err)
+		return
+	}
+
+	data, err := h.srv.List(req)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, data)
+}
+
+// Create 新增批次: POST /api/batches
+func (h *BatchHandler) Create(c *gin.Context) {
+	var input model.BatchInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		response.InvalidParams(c, err)
+		return
+	}
+
+	data, err := h.srv.Create(input)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Created(c, data)
+}
+
+// Update 修改批次: PUT /api/batches/:id
+func (h *BatchHandler) Update(c *gin.Context) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	var input model.BatchInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		response.InvalidParams(c, err)
+		return
+	}
+
+	data, err := h.srv.Update(id, input)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, data)
+}
+
+// Delete 删除批次: DELETE /api/batches/:id
+func (h *BatchHandler) Delete(c *gin.Context) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.srv.Delete(id); err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, nil)
+}
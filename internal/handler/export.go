@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"ProtectedArea/pkg/errcode"
+	"ProtectedArea/pkg/export"
+	"ProtectedArea/pkg/response"
+	"log"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// exportFormat 读取 format 参数: 为空表示返回 JSON，csv/xlsx 表示导出文件
+// 不支持的格式直接返回 400，此时 ok 为 false
+func exportFormat(c *gin.Context) (format string, ok bool) {
+	format = strings.ToLower(strings.TrimSpace(c.Query("format")))
+	if format == "" || export.IsSupported(format) {
+		return format, true
+	}
+	response.Error(c, errcode.InvalidParams.WithMessagef("不支持的导出格式(format): %s (仅支持 csv 和 xlsx)", format))
+	return "", false
+}
+
+// writeExport 以附件形式输出表格，文件名为 name_日期.格式
+// fn 负责写入表头和数据；出错时如果还没有向客户端写出任何内容，仍然返回统一的 JSON 错误
+func writeExport(c *gin.Context, format, name string, fn func(w export.Writer) error) {
+	fileName := name + "_" + time.Now().Format("20060102") + "." + format
+	header := c.Writer.Header()
+	header.Set("Content-Type", export.ContentType(format))
+	header.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": fileName}))
+
+	w, err := export.NewWriter(format, c.Writer)
+	if err == nil {
+		if err = fn(w); err == nil {
+			c.Status(http.StatusOK)
+			err = w.Close()
+		}
+	}
+	if err == nil {
+		return
+	}
+
+	if !c.Writer.Written() {
+		header.Del("Content-Type")
+		header.Del("Content-Disposition")
+		response.Error(c, err)
+		return
+	}
+	// 已经开始输出文件，只能记录日志并中断
+	log.Printf("[%s] 导出 %s 中断: %v", c.GetString(response.RequestIDKey), fileName, err)
+	c.Abort()
+}
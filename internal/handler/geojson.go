@@ -0,0 +1,168 @@
+package handler
+
+import (
+	"ProtectedArea/internal/middleware"
+	"ProtectedArea/internal/model"
+	"ProtectedArea/pkg/response"
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxGeoJSONFeatures GeoJSON 接口单次返回的要素上限
+const maxGeoJSONFeatures = 50000
+
+// errFeatureLimit 达到要素上限时用于中断遍历
+var errFeatureLimit = errors.New("feature limit reached")
+
+// geoFeature GeoJSON Point 要素
+type geoFeature struct {
+	Type       string            `json:"type"`
+	ID         string            `json:"id"`
+	Geometry   geoPoint          `json:"geometry"`
+	Properties geoSpotProperties `json:"properties"`
+}
+
+type geoPoint struct {
+	Type        string     `json:"type"`
+	Coordinates [2]float64 `json:"coordinates"`
+}
+
+// geoSpotProperties 要素属性，只输出地图渲染需要的字段
+type geoSpotProperties struct {
+	TBBH    string  `json:"tbbh"`
+	BHDL    string  `json:"bhdl"`
+	QLX     string  `json:"qlx"`
+	HLX     string  `json:"hlx"`
+	BHMJ    float64 `json:"bhmj"`
+	THBHDMC string  `json:"thbhdmc"`
+}
+
+// geoJSONWriter 以流的方式输出 FeatureCollection，不在内存中拼装整个集合
+type geoJSONWriter struct {
+	w     *bufio.Writer
+	count int
+}
+
+func newGeoJSONWriter(w io.Writer) *geoJSONWriter {
+	return &geoJSONWriter{w: bufio.NewWriter(w)}
+}
+
+// Begin 写入 FeatureCollection 的开头
+func (g *geoJSONWriter) Begin() error {
+	_, err := g.w.WriteString(`{"type":"FeatureCollection","features":[`)
+	return err
+}
+
+// WriteSpot 写入一个图斑要素
+func (g *geoJSONWriter) WriteSpot(spot *model.NatureData) error {
+	data, err := json.Marshal(geoFeature{
+		Type:     "Feature",
+		ID:       spot.TBBH,
+		Geometry: geoPoint{Type: "Point", Coordinates: [2]float64{spot.X, spot.Y}},
+		Properties: geoSpotProperties{
+			TBBH:    spot.TBBH,
+			BHDL:    spot.BHDL,
+			QLX:     spot.QLX,
+			HLX:     spot.HLX,
+			BHMJ:    spot.BHMJ,
+			THBHDMC: spot.THBHDMC,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if g.count > 0 {
+		if err := g.w.WriteByte(','); err != nil {
+			return err
+		}
+	}
+	g.count++
+	_, err = g.w.Write(data)
+	return err
+}
+
+// End 写入结尾，truncated 表示结果是否因数量上限被截断 (GeoJSON 允许的扩展成员)
+func (g *geoJSONWriter) End(truncated bool) error {
+	tail, _ := json.Marshal(map[string]interface{}{"truncated": truncated, "count": g.count})
+	// tail 形如 {"count":1,"truncated":false}，去掉开头的 { 拼接到集合对象中
+	if _, err := g.w.WriteString("],"); err != nil {
+		return err
+	}
+	if _, err := g.w.Write(tail[1:]); err != nil {
+		return err
+	}
+	return g.w.Flush()
+}
+
+// GetSpotsGeoJSON 图斑点位 GeoJSON: /api/spots.geojson?year=2024&scope=province&region_name=河北省&bbox=114,36,120,42&limit=1000
+func (h *NatureHandler) GetSpotsGeoJSON(c *gin.Context) {
+	var req model.GeoQueryRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.InvalidParams(c, err)
+		return
+	}
+	req.UserScope = middleware.CurrentScope(c)
+	if req.ProtectedType != "" {
+		req.ProtectedType = MapProtectedType(strings.TrimSpace(req.ProtectedType))
+	}
+
+	q := model.SpotStreamQuery{NatureQueryRequest: req.NatureQueryRequest, RequireLocation: true}
+	if req.BBox != "" {
+		bbox, err := model.ParseBBox(req.BBox)
+		if err != nil {
+			response.InvalidParams(c, err)
+			return
+		}
+		q.BBox = bbox
+	}
+
+	limit := req.Limit
+	if limit <= 0 || limit > maxGeoJSONFeatures {
+		limit = maxGeoJSONFeatures
+	}
+	// 多查一条，用来判断是否被截断
+	q.Limit = limit + 1
+
+	c.Header("Content-Type", "application/geo+json; charset=utf-8")
+
+	// 要素先写入缓冲区，缓冲区写满之前出错时仍可以返回正常的错误响应
+	gw := newGeoJSONWriter(c.Writer)
+	truncated := false
+	err := gw.Begin()
+	if err == nil {
+		err = h.srv.StreamSpots(q, func(spot *model.NatureData) error {
+			if gw.count >= limit {
+				truncated = true
+				return errFeatureLimit
+			}
+			return gw.WriteSpot(spot)
+		})
+		if errors.Is(err, errFeatureLimit) {
+			err = nil
+		}
+	}
+	if err == nil {
+		c.Status(http.StatusOK)
+		err = gw.End(truncated)
+	}
+	if err == nil {
+		return
+	}
+
+	if !c.Writer.Written() {
+		// 还没有输出任何内容，丢弃缓冲区中的数据，返回错误响应
+		c.Writer.Header().Del("Content-Type")
+		response.Error(c, err)
+		return
+	}
+	// 响应已经开始输出，只能记录日志并中断，客户端会得到不完整的 JSON
+	log.Printf("[%s] 输出 GeoJSON 中断: %v", c.GetString(response.RequestIDKey), err)
+	c.Abort()
+}
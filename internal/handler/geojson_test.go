@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"ProtectedArea/internal/model"
+	"ProtectedArea/internal/service"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// fakeStreamService 只实现 StreamSpots，按给定的图斑依次回调，最后返回 err
+type fakeStreamService struct {
+	service.NatureService
+	spots []model.NatureData
+	err   error
+}
+
+func (f *fakeStreamService) StreamSpots(q model.SpotStreamQuery, fn func(*model.NatureData) error) error {
+	for i := range f.spots {
+		if err := fn(&f.spots[i]); err != nil {
+			return err
+		}
+	}
+	return f.err
+}
+
+func TestGetSpotsGeoJSON(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	spots := []model.NatureData{
+		{TBBH: "A1", X: 116.1, Y: 39.9},
+		{TBBH: "A2", X: 116.2, Y: 39.8},
+	}
+
+	tests := []struct {
+		name          string
+		query         string
+		srv           *fakeStreamService
+		wantStatus    int
+		wantType      string
+		wantCount     float64
+		wantTruncated bool
+	}{
+		{
+			name:       "正常输出",
+			query:      "year=2024&scope=province",
+			srv:        &fakeStreamService{spots: spots},
+			wantStatus: http.StatusOK,
+			wantType:   "application/geo+json",
+			wantCount:  2,
+		},
+		{
+			name:          "超过数量上限时截断",
+			query:         "year=2024&scope=province&limit=1",
+			srv:           &fakeStreamService{spots: spots},
+			wantStatus:    http.StatusOK,
+			wantType:      "application/geo+json",
+			wantCount:     1,
+			wantTruncated: true,
+		},
+		{
+			name:       "输出前查询失败返回错误响应",
+			query:      "year=2024&scope=province",
+			srv:        &fakeStreamService{spots: spots, err: errors.New("connection reset")},
+			wantStatus: http.StatusInternalServerError,
+			wantType:   "application/json",
+		},
+		{
+			name:       "非法 bbox",
+			query:      "year=2024&scope=province&bbox=1,2,3",
+			srv:        &fakeStreamService{},
+			wantStatus: http.StatusBadRequest,
+			wantType:   "application/json",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/spots.geojson?"+tt.query, nil)
+
+			NewNatureHandler(tt.srv).GetSpotsGeoJSON(c)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d, body = %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, tt.wantType) {
+				t.Errorf("Content-Type = %q, want %q", ct, tt.wantType)
+			}
+
+			var body map[string]interface{}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("invalid JSON body %q: %v", w.Body, err)
+			}
+			if tt.wantStatus != http.StatusOK {
+				if _, ok := body["features"]; ok {
+					t.Errorf("error response contains partial GeoJSON: %s", w.Body)
+				}
+				return
+			}
+			if body["count"] != tt.wantCount || body["truncated"] != tt.wantTruncated {
+				t.Errorf("count = %v, truncated = %v, want %v, %v", body["count"], body["truncated"], tt.wantCount, tt.wantTruncated)
+			}
+		})
+	}
+}
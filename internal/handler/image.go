@@ -0,0 +1,255 @@
+package handler
+
+import (
+	"ProtectedArea/internal/middleware"
+	"ProtectedArea/internal/model"
+	"ProtectedArea/internal/service"
+	"ProtectedArea/pkg/errcode"
+	"ProtectedArea/pkg/export"
+	"ProtectedArea/pkg/response"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxImageFileSize 上传图片大小上限 (20MB)
+const maxImageFileSize = 20 << 20
+
+// ImageHandler 图斑图片 (多图、前后对比)
+type ImageHandler struct {
+	srv service.ImageService
+}
+
+func NewImageHandler(srv service.ImageService) *ImageHandler {
+	return &ImageHandler{srv: srv}
+}
+
+// ListSpotImages 图斑的全部图片: GET /api/spots/:tbbh/images
+func (h *ImageHandler) ListSpotImages(c *gin.Context) {
+	tbbh := strings.TrimSpace(c.Param("tbbh"))
+	if tbbh == "" {
+		response.Error(c, errcode.InvalidParams.WithMessage("图斑编号不能为空"))
+		return
+	}
+
+	data, err := h.srv.ListSpotImages(tbbh, middleware.CurrentScope(c))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	// Before/After 指向 Images 中的元素，这里填充一次即可
+	for i := range data.Images {
+		data.Images[i].URL = fmt.Sprintf("/api/images/%d", data.Images[i].ID)
+	}
+	response.Success(c, data)
+}
+
+// GetImage 获取单张图片文件: GET /api/images/:id?width=320&format=webp
+func (h *ImageHandler) GetImage(c *gin.Context) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+	var req model.ImageResizeRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.InvalidParams(c, err)
+		return
+	}
+
+	path, err := h.srv.GetImageFile(id, middleware.CurrentScope(c))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	h.serveImage(c, path, req)
+}
+
+// GetPatchImage 获取图斑图片 (旧接口): GET /api/image?tbbh=110109202202NR001&width=320
+func (h *ImageHandler) GetPatchImage(c *gin.Context) {
+	// 1. 获取参数
+	tbbh := c.Query("tbbh")
+	if tbbh == "" {
+		response.Error(c, errcode.InvalidParams.WithMessage("图斑编号不能为空"))
+		return
+	}
+	var req model.ImageResizeRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.InvalidParams(c, err)
+		return
+	}
+
+	// 2. 调用 Service 查找文件
+	path, err := h.srv.FindSpotImage(tbbh, middleware.CurrentScope(c))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	h.serveImage(c, path, req)
+}
+
+// serveImage 输出原图或缩略图，带 ETag/Last-Modified
+// http.ServeContent 会处理 If-None-Match / If-Modified-Since (命中时返回 304) 和 Range 请求
+func (h *ImageHandler) serveImage(c *gin.Context, path string, req model.ImageResizeRequest) {
+	img, err := h.srv.PrepareImage(path, req)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	f, err := os.Open(img.Path)
+	if err != nil {
+		response.Error(c, errcode.ImageNotFound)
+		return
+	}
+	defer f.Close()
+
+	// 图片需要登录后才能访问，只允许浏览器缓存；no-cache 表示每次都用 ETag 重新验证
+	c.Header("Cache-Control", "private, no-cache")
+	c.Header("ETag", img.ETag)
+	http.ServeContent(c.Writer, c.Request, filepath.Base(img.Path), img.ModTime, f)
+}
+
+// Rescan 重新扫描图片目录: POST /api/images/rescan
+func (h *ImageHandler) Rescan(c *gin.Context) {
+	data, err := h.srv.Rescan()
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, data)
+}
+
+// Upload 上传图斑图片: POST /api/spots/:tbbh/images (multipart: file, role, 可选 seq)
+func (h *ImageHandler) Upload(c *gin.Context) {
+	tbbh := strings.TrimSpace(c.Param("tbbh"))
+	file, ok := openUploadedImage(c)
+	if !ok {
+		return
+	}
+	defer file.Close()
+	var input model.ImageUploadInput
+	if err := c.ShouldBind(&input); err != nil {
+		response.InvalidParams(c, err)
+		return
+	}
+
+	img, err := h.srv.Upload(tbbh, input, file, middleware.CurrentOperator(c))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	img.URL = fmt.Sprintf("/api/images/%d", img.ID)
+	response.Created(c, img)
+}
+
+// Replace 替换图片文件: PUT /api/images/:id (multipart: file)
+func (h *ImageHandler) Replace(c *gin.Context) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+	file, ok := openUploadedImage(c)
+	if !ok {
+		return
+	}
+	defer file.Close()
+
+	img, err := h.srv.Replace(id, file, middleware.CurrentOperator(c))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	img.URL = fmt.Sprintf("/api/images/%d", img.ID)
+	response.Success(c, img)
+}
+
+// Delete 删除图片: DELETE /api/images/:id
+func (h *ImageHandler) Delete(c *gin.Context) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.srv.Delete(id, middleware.CurrentOperator(c)); err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, nil)
+}
+
+// ListAudits 图片变更记录: GET /api/images/audit?tbbh=110109202202NR001
+func (h *ImageHandler) ListAudits(c *gin.Context) {
+	var req model.ImageAuditQueryRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.InvalidParams(c, err)
+		return
+	}
+
+	data, err := h.srv.ListAudits(req)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, data)
+}
+
+// openUploadedImage 读取 multipart 中的 file 字段，出错时已写入响应
+func openUploadedImage(c *gin.Context) (multipart.File, bool) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImageFileSize)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			response.Error(c, errcode.PayloadTooLarge.WithMessage("上传图片不能超过 20MB"))
+			return nil, false
+		}
+		response.Error(c, errcode.InvalidParams.WithMessage("请上传图片文件(file)"))
+		return nil, false
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		response.Error(c, errcode.InvalidParams.WithMessage("读取上传文件失败"))
+		return nil, false
+	}
+	return file, true
+}
+
+// CheckImages 图片完整性检查: GET /api/images/check?year=2024&pc=202401&deep=true
+// format=csv/xlsx 时导出问题明细，再加 view=missing 时导出按年份/批次/省汇总的缺图统计
+func (h *ImageHandler) CheckImages(c *gin.Context) {
+	var req model.ImageCheckRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.InvalidParams(c, err)
+		return
+	}
+
+	format, ok := exportFormat(c)
+	if !ok {
+		return
+	}
+	if format != "" {
+		writeExport(c, format, "图片检查", func(w export.Writer) error {
+			report, err := h.srv.CheckImages(req)
+			if err != nil {
+				return err
+			}
+			return service.WriteImageCheckReport(report, req.View, w)
+		})
+		return
+	}
+
+	data, err := h.srv.CheckImages(req)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, data)
+}
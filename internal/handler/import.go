@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"ProtectedArea/internal/model"
+	"ProtectedArea/internal/service"
+	"ProtectedArea/pkg/errcode"
+	"ProtectedArea/pkg/response"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxImportFileSize 上传文件大小上限 (100MB)
+const maxImportFileSize = 100 << 20
+
+type ImportHandler struct {
+	srv service.ImportService
+}
+
+func NewImportHandler(srv service.ImportService) *ImportHandler {
+	return &ImportHandler{srv: srv}
+}
+
+// ImportSpots 图斑批量导入: POST /api/import/spots (multipart: file, 可选 year、pc、dry_run)
+func (h *ImportHandler) ImportSpots(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportFileSize)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			response.Error(c, errcode.PayloadTooLarge.WithMessage("上传文件不能超过 100MB"))
+			return
+		}
+		response.Error(c, errcode.InvalidParams.WithMessage("请上传文件(file)"))
+		return
+	}
+	// 文件解析完成后再绑定表单字段
+	var opts model.ImportOptions
+	if err := c.ShouldBind(&opts); err != nil {
+		response.InvalidParams(c, err)
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		response.Error(c, errcode.InvalidParams.WithMessage("读取上传文件失败"))
+		return
+	}
+	defer file.Close()
+
+	result, err := h.srv.ImportSpots(fileHeader.Filename, file, opts)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	// 部分行失败时仍然返回成功，由调用方根据报告中的 failed/errors 处理
+	response.Success(c, result)
+}
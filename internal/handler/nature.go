@@ -1,31 +1,19 @@
 package handler
 
 import (
+	"ProtectedArea/internal/middleware"
 	"ProtectedArea/internal/model"
 	"ProtectedArea/internal/service"
-	"net/http"
+	"ProtectedArea/pkg/errcode"
+	"ProtectedArea/pkg/export"
+	"ProtectedArea/pkg/response"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
-// ProtectedTypeMap 定义了中文保护地类型到英文缩写的映射
-var ProtectedTypeMap = map[string]string{
-	"NP":               "NP", // 允许直接传英文
-	"NR":               "NR",
-	"FP":               "FP",
-	"WP":               "WP",
-	"GP":               "GP",
-	"DP":               "DP",
-	"SH":               "SH",
-	"国家公园":         "NP",
-	"国家级自然保护区": "NR",
-	"森林公园":         "FP",
-	"湿地公园":         "WP",
-	"地质公园":         "GP",
-	"荒漠公园":         "DP",
-	"风景名胜区":       "SH",
-}
+// ProtectedTypeMap 定义了中文保护地类型到英文缩写的映射 (与 store 的原报类型比对共用 model 中的同一张表)
+var ProtectedTypeMap = model.ProtectedTypeMap
 
 type NatureHandler struct {
 	srv service.NatureService
@@ -35,70 +23,186 @@ func NewNatureHandler(srv service.NatureService) *NatureHandler {
 	return &NatureHandler{srv: srv}
 }
 
-// GetTrendStats 接口入口
+// GetTrendStats 趋势分析接口: /api/stats/trend?change_type=资源损毁,恢复治理&start_year=2020&end_year=2024&metric=area&breakdown=province
 func (h *NatureHandler) GetTrendStats(c *gin.Context) {
-	data, err := h.srv.GetTrendAnalysis()
+	var req model.TrendQueryRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.InvalidParams(c, err)
+		return
+	}
+	req.UserScope = middleware.CurrentScope(c)
+	if req.ProtectedType != "" {
+		req.ProtectedType = MapProtectedType(strings.TrimSpace(req.ProtectedType))
+	}
+
+	data, err := h.srv.GetTrendAnalysis(req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取统计数据失败"})
+		response.Error(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, data)
+	response.Success(c, data)
 }
 
-// GetYearlyOverview 1. 接口：获取年度概况
+// GetYearlyOverview 1. 接口：获取年度概况 (可选 protected_type、province 筛选)
 func (h *NatureHandler) GetYearlyOverview(c *gin.Context) {
-	year := c.Query("year")
-	if year == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "年份参数(year)不能为空"})
+	var req model.OverviewQueryRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Error(c, errcode.InvalidParams.WithMessage("年份参数(year)不能为空"))
 		return
 	}
+	req.UserScope = middleware.CurrentScope(c)
+	if req.ProtectedType != "" {
+		req.ProtectedType = MapProtectedType(strings.TrimSpace(req.ProtectedType))
+	}
 
-	data, err := h.srv.GetYearlyOverview(year)
+	data, err := h.srv.GetYearlyOverview(req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		response.Error(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, data)
+	response.Success(c, data)
 }
 
 // GetDamageBatchStats 2. 接口：获取资源损毁分批次统计
 func (h *NatureHandler) GetDamageBatchStats(c *gin.Context) {
 	year := c.Query("year")
 	if year == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "年份参数(year)不能为空"})
+		response.Error(c, errcode.InvalidParams.WithMessage("年份参数(year)不能为空"))
+		return
+	}
+
+	// format=csv/xlsx 时导出全部数据 (不分页)
+	format, ok := exportFormat(c)
+	if !ok {
+		return
+	}
+	if format != "" {
+		writeExport(c, format, "资源损毁分批次统计", func(w export.Writer) error {
+			return h.srv.ExportDamageBatchStats(year, middleware.CurrentScope(c), w)
+		})
+		return
+	}
+
+	data, err := h.srv.GetDamageAnalysisByBatch(year, middleware.CurrentScope(c))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, data)
+}
+
+// GetBatchStats 分批次统计: /api/stats/batch?year=2024&change_type=资源损毁,恢复治理&scope=province&region_name=河北省
+func (h *NatureHandler) GetBatchStats(c *gin.Context) {
+	var req model.BatchStatsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.InvalidParams(c, err)
 		return
 	}
+	req.UserScope = middleware.CurrentScope(c)
+	if req.ProtectedType != "" {
+		req.ProtectedType = MapProtectedType(strings.TrimSpace(req.ProtectedType))
+	}
+
+	data, err := h.srv.GetBatchStats(req)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, data)
+}
+
+// GetRanking 保护地/行政区排行榜
+func (h *NatureHandler) GetRanking(c *gin.Context) {
+	var req model.RankingRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.InvalidParams(c, err)
+		return
+	}
+	req.UserScope = middleware.CurrentScope(c)
+	if req.ProtectedType != "" {
+		req.ProtectedType = MapProtectedType(strings.TrimSpace(req.ProtectedType))
+	}
 
-	data, err := h.srv.GetDamageAnalysisByBatch(year)
+	data, err := h.srv.GetRanking(req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		response.Error(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, data)
+	response.Success(c, data)
 }
 
 func (h *NatureHandler) GetRegionStats(c *gin.Context) {
 	// 获取参数
 	year := c.Query("year")
 	scope := c.Query("scope")
-	name := c.Query("name") // 可选，没传就是空字符串
+	name := c.Query("name")                // 可选，没传就是空字符串
+	compareYear := c.Query("compare_year") // 可选，对比年份
 
 	// 必填校验
 	if year == "" || scope == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "参数 year 和 scope 不能为空"})
+		response.Error(c, errcode.InvalidParams.WithMessage("参数 year 和 scope 不能为空"))
+		return
+	}
+
+	// format=csv/xlsx 时导出全部数据 (不分页)
+	format, ok := exportFormat(c)
+	if !ok {
+		return
+	}
+	if format != "" {
+		writeExport(c, format, "行政区统计", func(w export.Writer) error {
+			return h.srv.ExportRegionStats(year, scope, name, middleware.CurrentScope(c), w)
+		})
 		return
 	}
 
 	// 调用 Service
-	data, err := h.srv.GetAdministrativeStats(year, scope, name)
+	data, err := h.srv.GetAdministrativeStats(year, scope, name, compareYear, middleware.CurrentScope(c))
 	if err != nil {
-		// 如果是业务逻辑报错（比如县级查下级），返回 400
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		// 业务逻辑报错（比如县级查下级）由错误码决定状态码，数据库错误返回 500
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, data)
+}
+
+// GetRegionTree 行政区树: /api/regions/tree?year=2024&depth=2&province=河北省
+func (h *NatureHandler) GetRegionTree(c *gin.Context) {
+	var req model.RegionTreeRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.InvalidParams(c, err)
+		return
+	}
+	req.UserScope = middleware.CurrentScope(c)
+	if req.ProtectedType != "" {
+		req.ProtectedType = MapProtectedType(strings.TrimSpace(req.ProtectedType))
+	}
+
+	data, err := h.srv.GetRegionTree(req)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, data)
+}
+
+// GetRegionDict 行政区字典: /api/regions?year=2024&depth=3
+func (h *NatureHandler) GetRegionDict(c *gin.Context) {
+	var req model.RegionDictRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.InvalidParams(c, err)
 		return
 	}
+	req.UserScope = middleware.CurrentScope(c)
 
-	c.JSON(http.StatusOK, data)
+	data, err := h.srv.GetRegionDict(req)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, data)
 }
 
 // GetProtectedAreaStats 接口1: 保护地统计
@@ -106,9 +210,10 @@ func (h *NatureHandler) GetProtectedAreaStats(c *gin.Context) {
 	var req model.NatureQueryRequest
 	// ShouldBindQuery 自动把 URL 参数绑定到结构体
 	if err := c.ShouldBindQuery(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		response.InvalidParams(c, err)
 		return
 	}
+	req.UserScope = middleware.CurrentScope(c)
 
 	// 1. 预处理 ProtectedType 字段
 	if req.ProtectedType != "" {
@@ -120,21 +225,34 @@ func (h *NatureHandler) GetProtectedAreaStats(c *gin.Context) {
 		req.ProtectedType = MapProtectedType(protectedTypeKey)
 	}
 
+	// format=csv/xlsx 时导出全部数据 (不分页)
+	format, ok := exportFormat(c)
+	if !ok {
+		return
+	}
+	if format != "" {
+		writeExport(c, format, "保护地统计", func(w export.Writer) error {
+			return h.srv.ExportProtectedAreaStats(req, w)
+		})
+		return
+	}
+
 	data, err := h.srv.GetProtectedAreaStats(req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		response.Error(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, data)
+	response.Success(c, data)
 }
 
 // GetSpotList 接口2: 图斑明细
 func (h *NatureHandler) GetSpotList(c *gin.Context) {
 	var req model.NatureQueryRequest
 	if err := c.ShouldBindQuery(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		response.InvalidParams(c, err)
 		return
 	}
+	req.UserScope = middleware.CurrentScope(c)
 
 	// 1. 预处理 ProtectedType 字段
 	if req.ProtectedType != "" {
@@ -146,24 +264,37 @@ func (h *NatureHandler) GetSpotList(c *gin.Context) {
 		req.ProtectedType = MapProtectedType(protectedTypeKey)
 	}
 
+	// format=csv/xlsx 时导出全部数据 (不分页)
+	format, ok := exportFormat(c)
+	if !ok {
+		return
+	}
+	if format != "" {
+		writeExport(c, format, "图斑明细", func(w export.Writer) error {
+			return h.srv.ExportSpotList(req, w)
+		})
+		return
+	}
+
 	data, err := h.srv.GetSpotList(req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		response.Error(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, data)
+	response.Success(c, data)
 }
 
 // GetTransitionStats 接口3: 流向分析 (饼图)
 func (h *NatureHandler) GetTransitionStats(c *gin.Context) {
 	var req model.NatureQueryRequest
 	if err := c.ShouldBindQuery(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		response.InvalidParams(c, err)
 		return
 	}
+	req.UserScope = middleware.CurrentScope(c)
 	// 接口3 必填 qlx
 	if req.QLX == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "前地类(qlx)参数必填"})
+		response.Error(c, errcode.InvalidParams.WithMessage("前地类(qlx)参数必填"))
 		return
 	}
 
@@ -177,12 +308,44 @@ func (h *NatureHandler) GetTransitionStats(c *gin.Context) {
 		req.ProtectedType = MapProtectedType(protectedTypeKey)
 	}
 
+	// format=csv/xlsx 时导出全部数据 (不分页)
+	format, ok := exportFormat(c)
+	if !ok {
+		return
+	}
+	if format != "" {
+		writeExport(c, format, "流向分析", func(w export.Writer) error {
+			return h.srv.ExportTransitionStats(req, w)
+		})
+		return
+	}
+
 	data, err := h.srv.GetTransitionStats(req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		response.Error(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, data)
+	response.Success(c, data)
+}
+
+// GetTransitionMatrix 地类流转矩阵 (前地类 × 后地类)，format=sankey 时返回桑基图格式
+func (h *NatureHandler) GetTransitionMatrix(c *gin.Context) {
+	var req model.TransitionMatrixRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.InvalidParams(c, err)
+		return
+	}
+	req.UserScope = middleware.CurrentScope(c)
+	if req.ProtectedType != "" {
+		req.ProtectedType = MapProtectedType(strings.TrimSpace(req.ProtectedType))
+	}
+
+	data, err := h.srv.GetTransitionMatrix(req)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, data)
 }
 
 // GetLargeSpots 大面积图斑预警接口
@@ -190,44 +353,162 @@ func (h *NatureHandler) GetLargeSpots(c *gin.Context) {
 	var req model.AlertQueryRequest
 	// 绑定参数
 	if err := c.ShouldBindQuery(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		response.InvalidParams(c, err)
 		return
 	}
+	req.UserScope = middleware.CurrentScope(c)
 
 	// 简单的业务校验 (可选)
 	if req.AlertArea < 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "预警面积必须大于等于0"})
+		response.Error(c, errcode.InvalidParams.WithMessage("预警面积必须大于等于0"))
+		return
+	}
+
+	// format=csv/xlsx 时导出全部数据 (不分页)
+	format, ok := exportFormat(c)
+	if !ok {
+		return
+	}
+	if format != "" {
+		writeExport(c, format, "大图斑预警", func(w export.Writer) error {
+			return h.srv.ExportLargeSpots(req, w)
+		})
 		return
 	}
 
 	data, err := h.srv.GetLargeSpots(req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		response.Error(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, data)
+	response.Success(c, data)
 }
 
-func (h *NatureHandler) GetPatchImage(c *gin.Context) {
-	// 1. 获取参数
-	tbbh := c.Query("tbbh")
+// GetSpotDetail 图斑详情: /api/spots/:tbbh
+func (h *NatureHandler) GetSpotDetail(c *gin.Context) {
+	tbbh := strings.TrimSpace(c.Param("tbbh"))
 	if tbbh == "" {
-		c.String(http.StatusBadRequest, "图斑编号不能为空")
+		response.Error(c, errcode.InvalidParams.WithMessage("图斑编号不能为空"))
 		return
 	}
 
-	// 2. 调用 Service 查找文件
-	filePath, exists := h.srv.GetImagePath(tbbh)
+	data, err := h.srv.GetSpotDetail(tbbh, middleware.CurrentScope(c))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	data.ProtectedTypeLabel = ProtectedTypeLabel(data.BHDLX)
+	response.Success(c, data)
+}
+
+// GetSpotLineage 图斑演变链: /api/spots/:tbbh/lineage
+func (h *NatureHandler) GetSpotLineage(c *gin.Context) {
+	tbbh := strings.TrimSpace(c.Param("tbbh"))
+	if tbbh == "" {
+		response.Error(c, errcode.InvalidParams.WithMessage("图斑编号不能为空"))
+		return
+	}
 
-	// 3. 根据结果返回
-	if !exists {
-		// 按照你的要求，返回纯文本
-		c.String(http.StatusOK, "暂无图片")
+	data, err := h.srv.GetSpotLineage(tbbh, middleware.CurrentScope(c))
+	if err != nil {
+		response.Error(c, err)
 		return
 	}
+	response.Success(c, data)
+}
 
-	// Gin 自带的方法，会自动设置 Content-Type 并流式传输文件
-	c.File(filePath)
+// GetRepeatChanges 重复变化报告: /api/stats/repeat-changes?year=2024&scope=province&region_name=河北省
+func (h *NatureHandler) GetRepeatChanges(c *gin.Context) {
+	var req model.NatureQueryRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.InvalidParams(c, err)
+		return
+	}
+	req.UserScope = middleware.CurrentScope(c)
+	if req.ProtectedType != "" {
+		req.ProtectedType = MapProtectedType(strings.TrimSpace(req.ProtectedType))
+	}
+
+	data, err := h.srv.GetRepeatChanges(req)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, data)
+}
+
+// GetRepeatChangeSpots 重复变化图斑明细 (分页): /api/stats/repeat-changes/spots?year=2024&scope=province&name=某保护地&page=1&page_size=50
+func (h *NatureHandler) GetRepeatChangeSpots(c *gin.Context) {
+	var req model.RepeatChangeSpotsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.InvalidParams(c, err)
+		return
+	}
+	req.UserScope = middleware.CurrentScope(c)
+	if req.ProtectedType != "" {
+		req.ProtectedType = MapProtectedType(strings.TrimSpace(req.ProtectedType))
+	}
+
+	data, err := h.srv.GetRepeatChangeSpots(req)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, data)
+}
+
+// GetAttributionDiffStats 核实/原报差异统计: /api/stats/attribution-diff?year=2024&scope=province&group_by=protected_area
+func (h *NatureHandler) GetAttributionDiffStats(c *gin.Context) {
+	req, ok := bindAttributionDiffRequest(c)
+	if !ok {
+		return
+	}
+
+	data, err := h.srv.GetAttributionDiffStats(req)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, data)
+}
+
+// GetAttributionDiffSpots 核实/原报不一致的图斑: /api/stats/attribution-diff/spots?year=2024&scope=province&field=province&page=1
+func (h *NatureHandler) GetAttributionDiffSpots(c *gin.Context) {
+	req, ok := bindAttributionDiffRequest(c)
+	if !ok {
+		return
+	}
+
+	data, err := h.srv.GetAttributionDiffSpots(req)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, data)
+}
+
+// bindAttributionDiffRequest 绑定差异查询参数并填充用户数据范围
+func bindAttributionDiffRequest(c *gin.Context) (model.AttributionDiffRequest, bool) {
+	var req model.AttributionDiffRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.InvalidParams(c, err)
+		return req, false
+	}
+	req.UserScope = middleware.CurrentScope(c)
+	if req.ProtectedType != "" {
+		req.ProtectedType = MapProtectedType(strings.TrimSpace(req.ProtectedType))
+	}
+	return req, true
+}
+
+// ProtectedTypeLabel 根据英文缩写反查保护地类型中文名，找不到时返回原值
+func ProtectedTypeLabel(abbr string) string {
+	for name, code := range ProtectedTypeMap {
+		if code == abbr && name != code {
+			return name
+		}
+	}
+	return abbr
 }
 
 func MapProtectedType(chineseName string) string {
@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"ProtectedArea/internal/middleware"
+	"ProtectedArea/internal/model"
+	"ProtectedArea/internal/service"
+	"ProtectedArea/pkg/errcode"
+	"ProtectedArea/pkg/export"
+	"ProtectedArea/pkg/response"
+	"archive/zip"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxPackSpots 资料包最多包含的图斑数
+const maxPackSpots = 5000
+
+// PackHandler 离线资料包 (图片 + 表格 + 点位)
+type PackHandler struct {
+	nature service.NatureService
+	images service.ImageService
+}
+
+func NewPackHandler(nature service.NatureService, images service.ImageService) *PackHandler {
+	return &PackHandler{nature: nature, images: images}
+}
+
+// GetSpotPack 下载筛选结果的离线资料包: /api/spots.zip?year=2024&scope=county&region_name=涞水县&change_type=资源损毁
+// 压缩包内容: spots.csv (全部字段)、spots.geojson (有坐标的图斑点位)、images/ (图斑的全部图片)
+// 直接边生成边输出，不落临时文件
+func (h *PackHandler) GetSpotPack(c *gin.Context) {
+	var req model.NatureQueryRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.InvalidParams(c, err)
+		return
+	}
+	req.UserScope = middleware.CurrentScope(c)
+	if req.ProtectedType != "" {
+		req.ProtectedType = MapProtectedType(strings.TrimSpace(req.ProtectedType))
+	}
+
+	// 1. 先查出全部图斑 (CSV、GeoJSON 和图片使用同一份结果)，超过上限时直接返回错误
+	var spots []model.NatureData
+	q := model.SpotStreamQuery{NatureQueryRequest: req, Limit: maxPackSpots + 1}
+	err := h.nature.StreamSpots(q, func(spot *model.NatureData) error {
+		spots = append(spots, *spot)
+		return nil
+	})
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	if len(spots) > maxPackSpots {
+		response.Error(c, errcode.InvalidParams.WithMessagef("图斑数量超过 %d 个，请缩小筛选范围", maxPackSpots))
+		return
+	}
+
+	tbbhs := make([]string, len(spots))
+	for i := range spots {
+		tbbhs[i] = spots[i].TBBH
+	}
+	images, err := h.images.PackImages(tbbhs)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	// 2. 边写边输出 ZIP
+	fileName := "图斑资料包_" + time.Now().Format("20060102") + ".zip"
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": fileName}))
+	c.Status(http.StatusOK)
+
+	if err := writeSpotPack(c.Writer, spots, images); err != nil {
+		// 响应头已经发出，只能记录日志并中断，客户端会得到不完整的压缩包
+		log.Printf("[%s] 输出资料包 %s 中断: %v", c.GetString(response.RequestIDKey), fileName, err)
+		c.Abort()
+	}
+}
+
+// writeSpotPack 依次写入 spots.csv、spots.geojson 和图片
+func writeSpotPack(w io.Writer, spots []model.NatureData, images []model.PackImage) error {
+	zw := zip.NewWriter(w)
+	now := time.Now()
+
+	// 1. spots.csv
+	entry, err := zw.CreateHeader(&zip.FileHeader{Name: "spots.csv", Method: zip.Deflate, Modified: now})
+	if err != nil {
+		return err
+	}
+	cw, err := export.NewWriter(export.FormatCSV, entry)
+	if err != nil {
+		return err
+	}
+	if err := cw.WriteRow(model.NatureFieldLabels()...); err != nil {
+		return err
+	}
+	for i := range spots {
+		if err := cw.WriteRow(spots[i].Values()...); err != nil {
+			return err
+		}
+	}
+	if err := cw.Close(); err != nil {
+		return err
+	}
+
+	// 2. spots.geojson (跳过没有坐标的图斑，与 /api/spots.geojson 一致)
+	entry, err = zw.CreateHeader(&zip.FileHeader{Name: "spots.geojson", Method: zip.Deflate, Modified: now})
+	if err != nil {
+		return err
+	}
+	gw := newGeoJSONWriter(entry)
+	if err := gw.Begin(); err != nil {
+		return err
+	}
+	for i := range spots {
+		if spots[i].X == 0 && spots[i].Y == 0 {
+			continue
+		}
+		if err := gw.WriteSpot(&spots[i]); err != nil {
+			return err
+		}
+	}
+	if err := gw.End(false); err != nil {
+		return err
+	}
+
+	// 3. 图片: JPEG/PNG 已经是压缩格式，直接存储不再压缩
+	for _, img := range images {
+		if err := writePackImage(zw, img); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// writePackImage 把一张图片写入压缩包，文件在打包过程中被删除时跳过
+func writePackImage(zw *zip.Writer, img model.PackImage) error {
+	f, err := os.Open(img.Path)
+	if err != nil {
+		log.Printf("资料包跳过无法读取的图片 %s: %v", img.Path, err)
+		return nil
+	}
+	defer f.Close()
+
+	entry, err := zw.CreateHeader(&zip.FileHeader{Name: img.Name, Method: zip.Store, Modified: img.ModTime})
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(entry, f)
+	return err
+}
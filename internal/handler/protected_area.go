@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"ProtectedArea/internal/model"
+	"ProtectedArea/internal/service"
+	"ProtectedArea/pkg/errcode"
+	"ProtectedArea/pkg/response"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ProtectedAreaHandler struct {
+	srv service.ProtectedAreaService
+}
+
+func NewProtectedAreaHandler(srv service.ProtectedAreaService) *ProtectedAreaHandler {
+	return &ProtectedAreaHandler{srv: srv}
+}
+
+// List 保护地名录列表: GET /api/protected-areas?type_code=NR&province=河北省&page=1
+func (h *ProtectedAreaHandler) List(c *gin.Context) {
+	var req model.ProtectedAreaQueryRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.InvalidParams(c, err)
+		return
+	}
+	if req.TypeCode != "" {
+		req.TypeCode = MapProtectedType(strings.TrimSpace(req.TypeCode))
+	}
+
+	data, err := h.srv.List(req)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, data)
+}
+
+// Get 保护地详情: GET /api/protected-areas/:id
+func (h *ProtectedAreaHandler) Get(c *gin.Context) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	data, err := h.srv.Get(id)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, data)
+}
+
+// Create 新增保护地: POST /api/protected-areas
+func (h *ProtectedAreaHandler) Create(c *gin.Context) {
+	var input model.ProtectedAreaInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		response.InvalidParams(c, err)
+		return
+	}
+	input.TypeCode = MapProtectedType(strings.TrimSpace(input.TypeCode))
+
+	data, err := h.srv.Create(input)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Created(c, data)
+}
+
+// Update 修改保护地: PUT /api/protected-areas/:id
+func (h *ProtectedAreaHandler) Update(c *gin.Context) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	var input model.ProtectedAreaInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		response.InvalidParams(c, err)
+		return
+	}
+	input.TypeCode = MapProtectedType(strings.TrimSpace(input.TypeCode))
+
+	data, err := h.srv.Update(id, input)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, data)
+}
+
+// Delete 删除保护地: DELETE /api/protected-areas/:id
+func (h *ProtectedAreaHandler) Delete(c *gin.Context) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.srv.Delete(id); err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, nil)
+}
+
+// parseIDParam 解析路径中的 :id 参数，失败时直接写入 400 响应
+func parseIDParam(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		response.Error(c, errcode.InvalidParams.WithMessage("无效的 id"))
+		return 0, false
+	}
+	return uint(id), true
+}
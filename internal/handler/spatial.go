@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"ProtectedArea/internal/middleware"
+	"ProtectedArea/internal/model"
+	"ProtectedArea/pkg/response"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetSpotsInBBox 范围查询: /api/spots/bbox?year=2024&scope=province&bbox=114,36,120,42&page=1
+func (h *NatureHandler) GetSpotsInBBox(c *gin.Context) {
+	var req model.BBoxQueryRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.InvalidParams(c, err)
+		return
+	}
+	req.UserScope = middleware.CurrentScope(c)
+	if req.ProtectedType != "" {
+		req.ProtectedType = MapProtectedType(strings.TrimSpace(req.ProtectedType))
+	}
+
+	data, err := h.srv.GetSpotsInBBox(req)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, data)
+}
+
+// GetSpotsWithinRadius 半径查询: /api/spots/radius?year=2024&scope=province&x=116.4&y=39.9&radius=5000
+func (h *NatureHandler) GetSpotsWithinRadius(c *gin.Context) {
+	var req model.RadiusQueryRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.InvalidParams(c, err)
+		return
+	}
+	req.UserScope = middleware.CurrentScope(c)
+	if req.ProtectedType != "" {
+		req.ProtectedType = MapProtectedType(strings.TrimSpace(req.ProtectedType))
+	}
+
+	data, err := h.srv.GetSpotsWithinRadius(req)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, data)
+}
+
+// GetSpotClusters 网格聚合 (低缩放级别下的点聚合): /api/spots/clusters?year=2024&scope=province&bbox=73,18,135,54&zoom=5
+func (h *NatureHandler) GetSpotClusters(c *gin.Context) {
+	var req model.ClusterQueryRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.InvalidParams(c, err)
+		return
+	}
+	req.UserScope = middleware.CurrentScope(c)
+	if req.ProtectedType != "" {
+		req.ProtectedType = MapProtectedType(strings.TrimSpace(req.ProtectedType))
+	}
+
+	data, err := h.srv.GetSpotClusters(req)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, data)
+}
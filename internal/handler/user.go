@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"ProtectedArea/internal/model"
+	"ProtectedArea/internal/service"
+	"ProtectedArea/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UserHandler 用户管理 (仅管理员)
+type UserHandler struct {
+	srv service.UserService
+}
+
+func NewUserHandler(srv service.UserService) *UserHandler {
+	return &UserHandler{srv: srv}
+}
+
+// List 用户列表: GET /api/users
+func (h *UserHandler) List(c *gin.Context) {
+	data, err := h.srv.List()
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, data)
+}
+
+// Create 新增用户: POST /api/users
+func (h *UserHandler) Create(c *gin.Context) {
+	var input model.UserInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		response.InvalidParams(c, err)
+		return
+	}
+
+	data, err := h.srv.Create(input)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Created(c, data)
+}
+
+// Update 修改用户: PUT /api/users/:id
+func (h *UserHandler) Update(c *gin.Context) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	var input model.UserInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		response.InvalidParams(c, err)
+		return
+	}
+
+	data, err := h.srv.Update(id, input)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, data)
+}
+
+// Delete 删除用户: DELETE /api/users/:id
+func (h *UserHandler) Delete(c *gin.Context) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.srv.Delete(id); err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, nil)
+}
+
+// ResetAPIKey 生成新的 API Key: POST /api/users/:id/api-key
+// 明文只在这里返回一次，服务端只保存哈希
+func (h *UserHandler) ResetAPIKey(c *gin.Context) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	key, err := h.srv.ResetAPIKey(id)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Success(c, gin.H{"api_key": key})
+}
@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"ProtectedArea/internal/model"
+	"ProtectedArea/internal/service"
+	"ProtectedArea/pkg/errcode"
+	"ProtectedArea/pkg/response"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// currentUserKey gin.Context 中保存当前用户的键
+	currentUserKey = "current_user"
+	// authDisabledKey 认证关闭时写入 gin.Context，RequireRole 据此放行
+	authDisabledKey = "auth_disabled"
+	// apiKeyHeader API Key 的 HTTP 头
+	apiKeyHeader = "X-API-Key"
+)
+
+// Authenticate 认证中间件: 支持 "Authorization: Bearer <JWT>" 或 "X-API-Key: <key>"
+// enabled 为 false 时不做认证，所有请求视为不受限的匿名用户
+func Authenticate(srv service.AuthService, enabled bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !enabled {
+			c.Set(authDisabledKey, true)
+			c.Next()
+			return
+		}
+
+		var (
+			user *model.User
+			err  error
+		)
+		if key := c.GetHeader(apiKeyHeader); key != "" {
+			user, err = srv.AuthenticateAPIKey(key)
+		} else if token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer "); ok && token != "" {
+			user, err = srv.AuthenticateToken(token)
+		} else {
+			err = errcode.Unauthorized
+		}
+		if err != nil {
+			response.Error(c, err)
+			return
+		}
+
+		c.Set(currentUserKey, user)
+		c.Next()
+	}
+}
+
+// RequireRole 只允许指定角色访问 (认证关闭时放行)
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetBool(authDisabledKey) {
+			c.Next()
+			return
+		}
+		user := CurrentUser(c)
+		if user == nil {
+			response.Error(c, errcode.Unauthorized)
+			return
+		}
+		for _, role := range roles {
+			if user.Role == role {
+				c.Next()
+				return
+			}
+		}
+		response.Error(c, errcode.Forbidden)
+	}
+}
+
+// CurrentUser 返回当前登录用户，未登录或认证关闭时返回 nil
+func CurrentUser(c *gin.Context) *model.User {
+	if v, ok := c.Get(currentUserKey); ok {
+		if u, ok := v.(*model.User); ok {
+			return u
+		}
+	}
+	return nil
+}
+
+// CurrentOperator 返回当前操作人 (用于审计)，认证关闭时用户名为 anonymous
+func CurrentOperator(c *gin.Context) model.Operator {
+	op := model.Operator{Username: "anonymous", IP: c.ClientIP()}
+	if u := CurrentUser(c); u != nil {
+		op.UserID, op.Username = u.ID, u.Username
+	}
+	return op
+}
+
+// CurrentScope 返回当前用户的数据范围，未登录或认证关闭时不受限制
+func CurrentScope(c *gin.Context) model.RegionScope {
+	if u := CurrentUser(c); u != nil {
+		return u.Scope()
+	}
+	return model.RegionScope{}
+}
@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"ProtectedArea/pkg/response"
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/gin-gonic/gin"
+)
+
+// requestIDHeader 请求 ID 的 HTTP 头，上游网关传入时沿用，否则自动生成
+const requestIDHeader = "X-Request-ID"
+
+// RequestID 为每个请求分配 ID，写入 gin.Context 和响应头，便于前后端对照日志
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(requestIDHeader)
+		if id == "" || len(id) > 64 {
+			id = newRequestID()
+		}
+		c.Set(response.RequestIDKey, id)
+		c.Header(requestIDHeader, id)
+		c.Next()
+	}
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
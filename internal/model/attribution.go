@@ -0,0 +1,66 @@
+package model
+
+// AttributionField 一组需要核对的字段: 核实值 (TH*) 与原报值 (YB*)
+type AttributionField struct {
+	Key   string // 接口中使用的名称，例如 province
+	TH    string // 核实字段列名，例如 THSHENG
+	YB    string // 原报字段列名，例如 YBSHENG
+	Label string // 中文含义
+	// YBValues 原报值到核实值取值的映射，比对前先转换；为空表示两者取值相同可以直接比较
+	YBValues map[string]string
+}
+
+// AttributionFields 参与核对的全部字段
+var AttributionFields = []AttributionField{
+	{"protected_area", "THBHDMC", "YBBHDMC", "保护地名称", nil},
+	// 核实的 BHDLX 是英文缩写 (NR、NP ...)，原报的 YBBHDLXBM 可能填的是中文类型名，统一转换为缩写后再比较
+	{"protected_type", "BHDLX", "YBBHDLXBM", "保护地类型", ProtectedTypeMap},
+	{"province", "THSHENG", "YBSHENG", "省", nil},
+	{"city", "THSHI", "YBSHI", "市", nil},
+	{"county", "THXIAN", "YBXIAN", "县", nil},
+}
+
+// AttributionDiffRequest 核实/原报差异查询参数
+type AttributionDiffRequest struct {
+	NatureQueryRequest
+
+	GroupBy string `form:"group_by"` // 统计接口: province (默认) 或 protected_area
+	Field   string `form:"field"`    // 明细接口: 只看某一个字段不一致的图斑，为空表示任一字段
+}
+
+// AttributionDiffStat 一个分组内的差异统计
+// 各 *Diff 字段为对应字段不一致的图斑个数，Mismatched 为至少一个字段不一致的图斑个数
+type AttributionDiffStat struct {
+	Name              string  `json:"name"`
+	Total             int64   `json:"total"`
+	Mismatched        int64   `json:"mismatched"`
+	MismatchRatio     float64 `json:"mismatch_ratio"` // 不一致占比 (%)
+	ProtectedAreaDiff int64   `json:"protected_area_diff"`
+	ProtectedTypeDiff int64   `json:"protected_type_diff"`
+	ProvinceDiff      int64   `json:"province_diff"`
+	CityDiff          int64   `json:"city_diff"`
+	CountyDiff        int64   `json:"county_diff"`
+}
+
+// AttributionDiffSpot 存在差异的图斑记录，DiffFields 为数据库判断出不一致的字段 Key，逗号分隔
+type AttributionDiffSpot struct {
+	NatureData
+	DiffFields string `gorm:"column:diff_fields"`
+}
+
+// AttributionDiffValue 一个字段的核实值与原报值
+type AttributionDiffValue struct {
+	Field    string `json:"field"`
+	Verified string `json:"verified"` // 核实值 (TH*)
+	Reported string `json:"reported"` // 原报值 (YB*)
+}
+
+// AttributionDiffItem 存在差异的图斑
+type AttributionDiffItem struct {
+	TBBH    string                 `json:"tbbh"`
+	BHDL    string                 `json:"bhdl"`
+	BHMJ    float64                `json:"bhmj"`
+	THBHDMC string                 `json:"thbhdmc"`
+	THSHENG string                 `json:"thsheng"`
+	Diffs   []AttributionDiffValue `json:"diffs"` // 只包含不一致的字段
+}
@@ -0,0 +1,53 @@
+package model
+
+import "time"
+
+// Batch 对应数据库表 batch (批次登记表)
+// 用正则表达式匹配 nature_data.PC，代替原先按 PC 后两位硬编码判断批次的做法
+type Batch struct {
+	ID          uint      `gorm:"column:id;primaryKey" json:"id"`
+	Name        string    `gorm:"column:name;size:64;not null" json:"name"`               // 显示名称，例如 第一批次；多条规则可以使用同一个名称
+	Pattern     string    `gorm:"column:pattern;size:255;not null" json:"pattern"`        // 匹配 PC 的正则表达式，例如 01$
+	Year        string    `gorm:"column:year;size:4;index" json:"year"`                   // 适用年份，为空表示所有年份
+	PeriodStart string    `gorm:"column:period_start;size:10" json:"period_start"`        // 影像时段开始 YYYY-MM-DD
+	PeriodEnd   string    `gorm:"column:period_end;size:10" json:"period_end"`            // 影像时段结束 YYYY-MM-DD
+	SortOrder   int       `gorm:"column:sort_order;not null;default:0" json:"sort_order"` // 显示顺序，同时也是匹配顺序
+	Remark      string    `gorm:"column:remark;size:255" json:"remark"`
+	CreatedAt   time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (Batch) TableName() string {
+	return "batch"
+}
+
+// 未登记批次的名称
+const (
+	BatchNameOther   = "其他批次" // PC 不匹配任何规则
+	BatchNameUnknown = "未知批次" // PC 为空
+)
+
+// DefaultBatches 批次表为空时写入的默认规则，与原先按 PC 后两位 01-04 判断的结果一致
+var DefaultBatches = []Batch{
+	{Name: "第一批次", Pattern: "01$", SortOrder: 1},
+	{Name: "第二批次", Pattern: "02$", SortOrder: 2},
+	{Name: "第三批次", Pattern: "03$", SortOrder: 3},
+	{Name: "第四批次", Pattern: "04$", SortOrder: 4},
+}
+
+// BatchQueryRequest 批次列表查询参数
+type BatchQueryRequest struct {
+	Year string `form:"year"` // 只看适用于该年份的批次 (含不限年份的规则)
+}
+
+// BatchInput 新增/修改批次的请求体
+type BatchInput struct {
+	Name        string `json:"name" binding:"required"`
+	Pattern     string `json:"pattern" binding:"required"`
+	Year        string `json:"year"`
+	PeriodStart string `json:"period_start"` // YYYY-MM-DD，可为空
+	PeriodEnd   string `json:"period_end"`   // YYYY-MM-DD，可为空
+	SortOrder   int    `json:"sort_order"`
+	Remark      string `json:"remark"`
+}
@@ -0,0 +1,16 @@
+package model
+
+// Comparison 本期值与对比期值
+type Comparison struct {
+	Current  float64  `json:"current"`  // 本期
+	Previous float64  `json:"previous"` // 对比期
+	Change   float64  `json:"change"`   // 增减量 = 本期 - 对比期
+	Rate     *float64 `json:"rate"`     // 增减率 (%)，对比期为 0 时无法计算，为 null
+}
+
+// StatComparison 个数和面积与对比年份的比较 (同比)
+type StatComparison struct {
+	Year  string     `json:"year"` // 对比年份
+	Count Comparison `json:"count"`
+	Area  Comparison `json:"area"`
+}
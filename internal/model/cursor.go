@@ -0,0 +1,18 @@
+package model
+
+// Keyset 游标 (keyset) 分页条件: 按 Column 排序 (同值按 TBBH 升序)，从 After 之后开始取 Limit 条
+// 与 OFFSET 分页相比，翻到很深的页也只需扫描 Limit 条记录，且并发导入时不会跳过或重复记录
+type Keyset struct {
+	Column string  // 排序列: TBBH 或 BHMJ
+	Desc   bool    // 是否降序
+	After  *Cursor // 上一页最后一条记录，为空表示第一页
+	Limit  int     // 最多返回的条数
+}
+
+// Cursor 游标分页的位置，编码后作为 next_cursor 返回给前端 (对前端不透明)
+type Cursor struct {
+	Column string  `json:"c"`           // 生成游标时的排序列，用于校验与当前请求一致
+	Desc   bool    `json:"d,omitempty"` // 生成游标时的排序方向
+	Value  float64 `json:"v,omitempty"` // 排序列的值 (按 BHMJ 排序时)
+	TBBH   string  `json:"t"`           // 图斑编号
+}
@@ -0,0 +1,127 @@
+package model
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// EarthRadiusMeters 地球平均半径 (米)，用于大圆距离计算
+const EarthRadiusMeters = 6371008.8
+
+// BBox 经纬度范围 (WGS84)，对应 nature_data 的 X (经度) / Y (纬度)
+type BBox struct {
+	MinX float64 `json:"minx"`
+	MinY float64 `json:"miny"`
+	MaxX float64 `json:"maxx"`
+	MaxY float64 `json:"maxy"`
+}
+
+// ParseBBox 解析 "minx,miny,maxx,maxy" 格式的范围参数
+func ParseBBox(s string) (*BBox, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 4 {
+		return nil, fmt.Errorf("bbox 格式应为 minx,miny,maxx,maxy: %q", s)
+	}
+
+	var v [4]float64
+	for i, p := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		// ParseFloat 接受 NaN、Inf，它们和任何数比较都不会超出范围，需要单独排除
+		if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+			return nil, fmt.Errorf("bbox 第 %d 个值不是合法数字: %q", i+1, p)
+		}
+		v[i] = f
+	}
+
+	b := &BBox{MinX: v[0], MinY: v[1], MaxX: v[2], MaxY: v[3]}
+	if b.MinX > b.MaxX || b.MinY > b.MaxY {
+		return nil, fmt.Errorf("bbox 的最小值不能大于最大值: %q", s)
+	}
+	if b.MinX < -180 || b.MaxX > 180 || b.MinY < -90 || b.MaxY > 90 {
+		return nil, fmt.Errorf("bbox 超出经纬度范围: %q", s)
+	}
+	return b, nil
+}
+
+// GeoQueryRequest GeoJSON 导出参数: 在通用筛选条件基础上增加范围和数量上限
+type GeoQueryRequest struct {
+	NatureQueryRequest
+
+	BBox  string `form:"bbox"`  // 可选: minx,miny,maxx,maxy
+	Limit int    `form:"limit"` // 可选: 最多返回的要素个数，不能超过服务端上限
+}
+
+// SpotStreamQuery 逐条遍历图斑时使用的查询条件
+type SpotStreamQuery struct {
+	NatureQueryRequest
+
+	BBox            *BBox // 为空表示不限制范围
+	Limit           int   // <= 0 表示不限制条数
+	RequireLocation bool  // 为 true 时跳过没有坐标 (X、Y 均为 0) 的图斑
+}
+
+// RadiusBBox 计算以 (lng, lat) 为圆心、radius 米为半径的圆的外接矩形
+// 用于在精确计算大圆距离之前先做一次粗筛，结果会裁剪到合法经纬度范围内
+func RadiusBBox(lng, lat, radius float64) BBox {
+	dLat := radius / EarthRadiusMeters * 180 / math.Pi
+	dLng := 180.0 // 靠近极点时经度范围退化为全部
+	if cos := math.Cos(lat * math.Pi / 180); cos > 1e-6 {
+		dLng = math.Min(dLat/cos, 180)
+	}
+	return BBox{
+		MinX: math.Max(lng-dLng, -180),
+		MinY: math.Max(lat-dLat, -90),
+		MaxX: math.Min(lng+dLng, 180),
+		MaxY: math.Min(lat+dLat, 90),
+	}
+}
+
+// BBoxQueryRequest 范围查询参数
+type BBoxQueryRequest struct {
+	NatureQueryRequest
+
+	BBox string `form:"bbox" binding:"required"` // minx,miny,maxx,maxy
+}
+
+// RadiusQueryRequest 半径查询参数
+type RadiusQueryRequest struct {
+	NatureQueryRequest
+
+	X      *float64 `form:"x" binding:"required"`      // 圆心经度
+	Y      *float64 `form:"y" binding:"required"`      // 圆心纬度
+	Radius float64  `form:"radius" binding:"required"` // 半径 (米)
+}
+
+// SpotLocationItem 空间查询返回的图斑 (带坐标)
+type SpotLocationItem struct {
+	TBBH     string   `json:"tbbh"`
+	BHDL     string   `json:"bhdl"`
+	QLX      string   `json:"qlx"`
+	HLX      string   `json:"hlx"`
+	X        float64  `json:"x"`
+	Y        float64  `json:"y"`
+	BHMJ     float64  `json:"bhmj"`
+	THBHDMC  string   `json:"thbhdmc"`
+	Distance *float64 `json:"distance,omitempty"` // 到圆心的距离 (米)，仅半径查询返回
+}
+
+// ClusterQueryRequest 网格聚合参数
+type ClusterQueryRequest struct {
+	NatureQueryRequest
+
+	BBox string `form:"bbox" binding:"required"` // 当前地图视野 minx,miny,maxx,maxy
+	Zoom int    `form:"zoom"`                    // 地图缩放级别 (0-22)
+}
+
+// SpotCluster 一个网格单元的聚合结果
+type SpotCluster struct {
+	CellX int64   `json:"cell_x"`         // 网格列号 (相对 bbox 左下角)
+	CellY int64   `json:"cell_y"`         // 网格行号
+	Count int64   `json:"count"`          // 图斑个数
+	Area  float64 `json:"area"`           // BHMJ 合计
+	X     float64 `json:"x"`              // 质心经度 (单元内图斑坐标的平均值)
+	Y     float64 `json:"y"`              // 质心纬度
+	TBBH  string  `json:"tbbh,omitempty"` // 单元内只有一个图斑时返回其编号，方便前端直接展示
+}
@@ -0,0 +1,43 @@
+package model
+
+import "testing"
+
+func TestParseBBox(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    BBox
+		wantErr bool
+	}{
+		{name: "合法范围", input: "114,36,120,42", want: BBox{MinX: 114, MinY: 36, MaxX: 120, MaxY: 42}},
+		{name: "允许空格", input: " 114.5 , 36 ,120, 42.25 ", want: BBox{MinX: 114.5, MinY: 36, MaxX: 120, MaxY: 42.25}},
+		{name: "全球范围", input: "-180,-90,180,90", want: BBox{MinX: -180, MinY: -90, MaxX: 180, MaxY: 90}},
+		{name: "个数不对", input: "114,36,120", wantErr: true},
+		{name: "不是数字", input: "114,36,abc,42", wantErr: true},
+		{name: "NaN", input: "NaN,36,120,42", wantErr: true},
+		{name: "NaN 最大值", input: "114,36,120,nan", wantErr: true},
+		{name: "Inf", input: "114,36,+Inf,42", wantErr: true},
+		{name: "负 Inf", input: "-Inf,36,120,42", wantErr: true},
+		{name: "最小值大于最大值", input: "120,36,114,42", wantErr: true},
+		{name: "超出经度范围", input: "114,36,181,42", wantErr: true},
+		{name: "超出纬度范围", input: "114,-91,120,42", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseBBox(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseBBox(%q) = %+v, want error", tt.input, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseBBox(%q) unexpected err: %v", tt.input, err)
+			}
+			if *got != tt.want {
+				t.Errorf("ParseBBox(%q) = %+v, want %+v", tt.input, *got, tt.want)
+			}
+		})
+	}
+}
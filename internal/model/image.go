@@ -0,0 +1,192 @@
+package model
+
+import "time"
+
+// 图片角色
+const (
+	ImageRoleBefore = "before" // 变化前影像 (对应上期时间 SQSJ)
+	ImageRoleAfter  = "after"  // 变化后影像 (对应本期时间 BQSJ)
+	ImageRoleField  = "field"  // 外业照片
+)
+
+// SpotImage 图斑图片索引，由扫描图片目录生成，一个图斑可以有多张图片
+// 文件命名规则 (相对于图片根目录，可以放在子目录中):
+//
+//	TBBH.jpg             旧的单图命名，视为变化后影像
+//	TBBH_before.jpg      变化前影像
+//	TBBH_after.jpg       变化后影像
+//	TBBH_field_1.jpg     外业照片，_N 为序号 (before/after 同样可以带序号)
+type SpotImage struct {
+	ID          uint      `gorm:"column:id;primaryKey" json:"id"`
+	TBBH        string    `gorm:"column:tbbh;size:64;not null;index" json:"tbbh"`
+	Role        string    `gorm:"column:role;size:16;not null" json:"role"`           // before, after, field
+	Seq         int       `gorm:"column:seq;not null;default:1" json:"seq"`           // 同一角色下的序号，从 1 开始
+	Path        string    `gorm:"column:path;size:512;not null;uniqueIndex" json:"-"` // 相对于图片根目录的路径 (使用 /)
+	Format      string    `gorm:"column:format;size:16" json:"format"`                // jpeg 或 png
+	Width       int       `gorm:"column:width" json:"width"`                          // 像素宽度
+	Height      int       `gorm:"column:height" json:"height"`                        // 像素高度
+	Size        int64     `gorm:"column:size" json:"size"`                            // 文件大小 (字节)
+	ModTime     time.Time `gorm:"column:mod_time" json:"mod_time"`                    // 文件修改时间，重新扫描时用于判断文件是否变化
+	CaptureDate string    `gorm:"column:capture_date;size:32" json:"capture_date"`    // 拍摄日期: 前/后影像取图斑的 SQSJ/BQSJ，外业照片取文件修改日期
+	URL         string    `gorm:"-" json:"url"`                                       // 获取图片的接口地址，由 handler 填充
+	CreatedAt   time.Time `gorm:"column:created_at" json:"-"`
+	UpdatedAt   time.Time `gorm:"column:updated_at" json:"-"`
+}
+
+// TableName 指定表名
+func (SpotImage) TableName() string {
+	return "spot_image"
+}
+
+// SpotImages 图斑的全部图片，Before/After 为前后对比 (滑块) 使用的一对图片，缺失时为 null
+type SpotImages struct {
+	TBBH   string      `json:"tbbh"`
+	Before *SpotImage  `json:"before"`
+	After  *SpotImage  `json:"after"`
+	Images []SpotImage `json:"images"` // 按 变化前、变化后、外业照片 和序号排序
+}
+
+// ImageUploadInput 上传图片的表单字段 (文件本身为 file 字段)
+type ImageUploadInput struct {
+	Role string `form:"role" binding:"required,oneof=before after field"` // before, after, field
+	// Seq 序号，为 0 时: 变化前/后影像为 1，外业照片为现有最大序号 + 1
+	Seq int `form:"seq" binding:"min=0"`
+}
+
+// 图片变更类型
+const (
+	ImageActionUpload  = "upload"
+	ImageActionReplace = "replace"
+	ImageActionDelete  = "delete"
+)
+
+// ImageAudit 图片变更记录 (谁在什么时候上传/替换/删除了哪张图片)
+type ImageAudit struct {
+	ID         uint      `gorm:"column:id;primaryKey" json:"id"`
+	ImageID    uint      `gorm:"column:image_id;index" json:"image_id"`
+	TBBH       string    `gorm:"column:tbbh;size:64;not null;index" json:"tbbh"`
+	Action     string    `gorm:"column:action;size:16;not null" json:"action"` // upload, replace, delete
+	Role       string    `gorm:"column:role;size:16" json:"role"`
+	Seq        int       `gorm:"column:seq" json:"seq"`
+	Path       string    `gorm:"column:path;size:512" json:"path"`         // 变更后的文件 (删除时为被删除的文件)
+	OldPath    string    `gorm:"column:old_path;size:512" json:"old_path"` // 替换前的文件
+	Size       int64     `gorm:"column:size" json:"size"`                  // 新文件大小 (字节)
+	SHA256     string    `gorm:"column:sha256;size:64" json:"sha256"`      // 新文件内容的 SHA-256
+	OperatorID uint      `gorm:"column:operator_id" json:"operator_id"`
+	Operator   string    `gorm:"column:operator;size:64" json:"operator"` // 操作人用户名
+	ClientIP   string    `gorm:"column:client_ip;size:64" json:"client_ip"`
+	CreatedAt  time.Time `gorm:"column:created_at;index" json:"created_at"`
+}
+
+// TableName 指定表名
+func (ImageAudit) TableName() string {
+	return "image_audit"
+}
+
+// ImageAuditQueryRequest 图片变更记录查询参数
+type ImageAuditQueryRequest struct {
+	TBBH     string `form:"tbbh"` // 图斑编号 (可选)
+	Page     int    `form:"page,default=1"`
+	PageSize int    `form:"page_size,default=20"`
+}
+
+// PackImage 打包下载时的一张图片
+type PackImage struct {
+	TBBH    string
+	Name    string    // 压缩包内的路径，例如 images/110109202202NR001_before.jpg
+	Path    string    // 文件路径
+	ModTime time.Time // 文件修改时间
+}
+
+// ImageScanResult 扫描图片目录的结果
+type ImageScanResult struct {
+	Scanned  int    `json:"scanned"`  // 识别到的图片文件数
+	Added    int    `json:"added"`    // 新增的索引
+	Updated  int    `json:"updated"`  // 文件或拍摄日期有变化而更新的索引
+	Removed  int    `json:"removed"`  // 文件已不存在而删除的索引
+	Skipped  int    `json:"skipped"`  // 无法识别的文件 (命名不符合规则或无法解析图片)
+	Duration string `json:"duration"` // 耗时
+}
+
+// ImageResizeRequest 获取图片时的缩放参数，全部为空时返回原图
+// 宽高向上取整到标准尺寸 (64/128/256/320/480/640/800/1024/...)，质量取整到 10 的倍数
+type ImageResizeRequest struct {
+	Width   int    `form:"width"`   // 最大宽度 (像素)
+	Height  int    `form:"height"`  // 最大高度 (像素)
+	Quality int    `form:"quality"` // JPEG 质量 1-100，默认 80
+	Format  string `form:"format"`  // 输出格式: jpeg, png, webp，默认与原图相同
+}
+
+// IsZero 是否未指定任何缩放参数
+func (r ImageResizeRequest) IsZero() bool {
+	return r.Width == 0 && r.Height == 0 && r.Quality == 0 && r.Format == ""
+}
+
+// ImageFile 输出给客户端的图片文件 (原图或缩略图缓存)
+type ImageFile struct {
+	Path    string    // 文件路径
+	ModTime time.Time // 原图的修改时间，用于 Last-Modified
+	ETag    string    // 带引号的 ETag
+}
+
+// 图片检查发现的问题类型
+const (
+	ImageIssueMissing   = "missing"   // 图斑没有可用的图片
+	ImageIssueOrphan    = "orphan"    // 图片文件名中的图斑编号在 nature_data 中不存在
+	ImageIssueCorrupt   = "corrupt"   // 图片无法读取或已损坏
+	ImageIssueDuplicate = "duplicate" // 同一图斑、角色和序号有多个文件，例如 TBBH.jpg 和 TBBH.png
+)
+
+// 图片检查报告的表格视图
+const (
+	ImageCheckViewIssues  = "issues"  // 问题明细 (默认)
+	ImageCheckViewMissing = "missing" // 按年份/批次/省汇总的缺图统计
+)
+
+// ImageCheckRequest 图片完整性检查参数
+// Year/PC 只限定检查缺图的图斑范围，孤立文件、损坏和重复始终针对整个图片目录
+type ImageCheckRequest struct {
+	Year string `form:"year"`
+	PC   string `form:"pc"`
+	Deep bool   `form:"deep"` // 完整解码每张图片 (较慢)；否则只读取文件头，且文件未变化时沿用索引中的结果
+	View string `form:"view"` // 导出表格时使用: issues (默认) 或 missing
+}
+
+// ImageCheckReport 图片完整性检查报告
+type ImageCheckReport struct {
+	Summary ImageCheckSummary   `json:"summary"`
+	Missing []ImageMissingGroup `json:"missing"` // 按年份、批次、省汇总，只包含有图斑的分组
+	Issues  []ImageIssue        `json:"issues"`  // 按 缺图、孤立文件、损坏、重复 和图斑编号排序
+}
+
+// ImageCheckSummary 检查结果汇总
+type ImageCheckSummary struct {
+	Spots      int    `json:"spots"`       // 检查的图斑数
+	WithImages int    `json:"with_images"` // 其中有可用图片的图斑数
+	Files      int    `json:"files"`       // 扫描到的图片文件数
+	Missing    int    `json:"missing"`
+	Orphan     int    `json:"orphan"`
+	Corrupt    int    `json:"corrupt"`
+	Duplicate  int    `json:"duplicate"`
+	Duration   string `json:"duration"` // 耗时
+}
+
+// ImageMissingGroup 一个年份/批次/省的缺图统计
+type ImageMissingGroup struct {
+	Year     string `json:"year"`
+	PC       string `json:"pc"`
+	Province string `json:"province"`
+	Spots    int    `json:"spots"`   // 图斑数
+	Missing  int    `json:"missing"` // 缺图的图斑数
+}
+
+// ImageIssue 一个问题: 缺图时 Path 为空，孤立文件时 Year/PC/Province 为空
+type ImageIssue struct {
+	Type     string `json:"type"` // missing, orphan, corrupt, duplicate
+	TBBH     string `json:"tbbh"`
+	Path     string `json:"path"` // 相对于图片根目录的路径，重复时为第一个文件
+	Year     string `json:"year"`
+	PC       string `json:"pc"`
+	Province string `json:"province"`
+	Detail   string `json:"detail"` // 说明，例如错误信息或重复的全部文件
+}
@@ -0,0 +1,25 @@
+package model
+
+// ImportOptions 图斑导入选项
+type ImportOptions struct {
+	Year   string `form:"year"`    // 文件中没有 year 列或为空时使用的年份
+	PC     string `form:"pc"`      // 文件中没有 PC 列或为空时使用的批次
+	DryRun bool   `form:"dry_run"` // 只校验不入库
+}
+
+// ImportRowError 单行的校验/入库错误
+type ImportRowError struct {
+	Row    int      `json:"row"`  // 文件中的行号 (表头为第 1 行)
+	TBBH   string   `json:"tbbh"` // 图斑编号 (可能为空)
+	Errors []string `json:"errors"`
+}
+
+// ImportResult 导入结果报告
+type ImportResult struct {
+	FileName  string           `json:"file_name"`
+	DryRun    bool             `json:"dry_run"`
+	TotalRows int              `json:"total_rows"` // 数据行数 (不含表头和空行)
+	Succeeded int              `json:"succeeded"`  // 成功写入 (或 dry_run 时通过校验) 的行数
+	Failed    int              `json:"failed"`
+	Errors    []ImportRowError `json:"errors"`
+}
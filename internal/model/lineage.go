@@ -0,0 +1,62 @@
+package model
+
+// LineageNode 图斑演变链中的一个节点
+type LineageNode struct {
+	TBBH    string  `json:"tbbh"`
+	SQTBBH  string  `json:"sqtbbh"` // 上期图斑编号
+	Year    string  `json:"year"`
+	PC      string  `json:"pc"`
+	BHDL    string  `json:"bhdl"`
+	QLX     string  `json:"qlx"`
+	HLX     string  `json:"hlx"`
+	BHMJ    float64 `json:"bhmj"`
+	THBHDMC string  `json:"thbhdmc"`
+	SFCXBH  int     `json:"sfcxbh"`
+	// Depth 相对于查询图斑的代数: 负数为前期图斑 (-1 为上一期)，0 为自身，正数为后续图斑
+	Depth int `json:"depth"`
+}
+
+// SpotLineage 图斑演变链，Nodes 按 Depth 从早到晚排序
+type SpotLineage struct {
+	TBBH      string        `json:"tbbh"`
+	Nodes     []LineageNode `json:"nodes"`
+	Truncated bool          `json:"truncated"` // 链条超过最大追溯深度时为 true
+}
+
+// RepeatChangeSpot 重复变化图斑 (SFCXBH=1) 及其上期信息
+type RepeatChangeSpot struct {
+	TBBH     string  `json:"tbbh"`
+	BHDL     string  `json:"bhdl"`
+	QLX      string  `json:"qlx"`
+	HLX      string  `json:"hlx"`
+	BHMJ     float64 `json:"bhmj"`
+	SQTBBH   string  `json:"sqtbbh"`
+	PrevYear string  `json:"prev_year"` // 上期图斑年份，找不到上期图斑时为空
+	PrevBHDL string  `json:"prev_bhdl"` // 上期变化地类，找不到上期图斑时为空
+}
+
+// RepeatChangeGroup 一个保护地内的重复变化图斑汇总
+// 图斑明细通过 /api/stats/repeat-changes/spots?name=保护地名称 分页获取
+type RepeatChangeGroup struct {
+	Name  string  `json:"name"` // 保护地名称
+	Count int64   `json:"count"`
+	Area  float64 `json:"area"`
+	// Transitions 上期变化地类 -> 本期变化地类 的个数，例如 {"资源损毁→恢复治理": 3, "资源损毁→资源损毁": 1}
+	Transitions map[string]int64 `json:"transitions"`
+}
+
+// RepeatChangeTransitionStat 按 保护地、上期变化地类、本期变化地类 分组的统计结果
+type RepeatChangeTransitionStat struct {
+	Name     string // 保护地名称
+	PrevBHDL string // 上期变化地类，找不到上期图斑时为空
+	BHDL     string // 本期变化地类
+	Count    int64
+	Area     float64
+}
+
+// RepeatChangeSpotsRequest 重复变化图斑明细查询参数
+type RepeatChangeSpotsRequest struct {
+	NatureQueryRequest
+
+	Name string `form:"name"` // 保护地名称 (可选)
+}
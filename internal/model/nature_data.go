@@ -1,5 +1,7 @@
 package model
 
+import "fmt"
+
 // NatureData 对应数据库表 nature_data
 type NatureData struct {
 	TBBH      string  `gorm:"column:TBBH;primaryKey" json:"tbbh"` // 图斑编号 (设为主键)
@@ -37,12 +39,82 @@ func (NatureData) TableName() string {
 	return "nature_data"
 }
 
+// NatureField 描述 nature_data 的一个字段: 数据库列名、JSON 名和中文含义
+// 导入时用来识别表头 (列名或中文名均可)，导出时用来生成中文表头
+type NatureField struct {
+	Column string // 数据库列名，例如 TBBH
+	JSON   string // JSON 字段名，例如 tbbh
+	Label  string // 中文含义，例如 图斑编号
+}
+
+// NatureFields nature_data 全部字段，顺序与 NatureData 结构体一致
+var NatureFields = []NatureField{
+	{"TBBH", "tbbh", "图斑编号"},
+	{"BHDL", "bhdl", "变化地类"},
+	{"QLX", "qlx", "前地类"},
+	{"HLX", "hlx", "后地类"},
+	{"X", "x", "经度"},
+	{"Y", "y", "纬度"},
+	{"BHMJ", "bhmj", "图斑面积"},
+	{"THBHDMC", "thbhdmc", "保护地名称"},
+	{"BHDLX", "bhdlx", "保护地类型"},
+	{"PC", "pc", "批次"},
+	{"BQSJ", "bqsj", "本期时间"},
+	{"SQSJ", "sqsj", "上期时间"},
+	{"THXDM", "thxdm", "县级行政区代码"},
+	{"THSHENG", "thsheng", "省"},
+	{"THSHI", "thshi", "市"},
+	{"THXIAN", "thxian", "县"},
+	{"SFCXBH", "sfcxbh", "是否重复变化"},
+	{"SQTBBH", "sqtbbh", "上期图斑编号"},
+	{"THBZ", "thbz", "备注"},
+	{"YBBHDMC", "ybbhdmc", "原报保护地名称"},
+	{"YBBHDLXBM", "ybbhdlxbm", "原报保护地类型编码"},
+	{"YBSHENG", "ybsheng", "原报省"},
+	{"YBSHI", "ybshi", "原报市"},
+	{"YBXIAN", "ybxian", "原报县"},
+	{"YBXBM", "ybxbm", "原报县代码"},
+	{"YBBZ1", "ybbz1", "原报备注1"},
+	{"YBBZ2", "ybbz2", "原报备注2"},
+	{"year", "year", "年份"},
+}
+
+// NatureFieldLabel 返回列名对应的中文含义，未知列原样返回
+func NatureFieldLabel(column string) string {
+	for _, f := range NatureFields {
+		if f.Column == column {
+			return f.Label
+		}
+	}
+	return column
+}
+
+// NatureFieldLabels 按 NatureFields 的顺序返回全部字段的中文含义，用作导出表头
+func NatureFieldLabels() []interface{} {
+	labels := make([]interface{}, len(NatureFields))
+	for i, f := range NatureFields {
+		labels[i] = f.Label
+	}
+	return labels
+}
+
+// Values 按 NatureFields 的顺序返回全部字段值，用于导出
+func (d *NatureData) Values() []interface{} {
+	return []interface{}{
+		d.TBBH, d.BHDL, d.QLX, d.HLX, d.X, d.Y, d.BHMJ, d.THBHDMC, d.BHDLX, d.PC,
+		d.BQSJ, d.SQSJ, d.THXDM, d.THSHENG, d.THSHI, d.THXIAN, d.SFCXBH, d.SQTBBH, d.THBZ,
+		d.YBBHDMC, d.YBBHDLXBM, d.YBSHENG, d.YBSHI, d.YBXIAN, d.YBXBM, d.YBBZ1, d.YBBZ2, d.Year,
+	}
+}
+
 // StatResult 用于接收数据库 Group By 查询出的原始结果
 // 因为 GORM 聚合查询的结果往往不对应原始表结构，所以定义这个 DTO (Data Transfer Object)
 type StatResult struct {
-	Year  string `json:"year"`
-	BHDL  string `json:"bhdl"`
-	Count int64  `json:"count"`
+	Year      string  `json:"year"`
+	BHDL      string  `json:"bhdl"`
+	GroupName string  `json:"group_name"` // 细分维度的取值 (省份/保护地类型/批次)，不细分时为空
+	Count     int64   `json:"count"`
+	Area      float64 `json:"area"`
 }
 
 // BatchStatResult --- 在 internal/model/nature_data.go 中补充一个 DTO 结构体 ---
@@ -74,6 +146,25 @@ type NatureQueryRequest struct {
 	// 分页参数
 	Page     int `form:"page,default=1"`
 	PageSize int `form:"page_size,default=10"`
+
+	// 对比年份 (可选)，保护地统计和流向分析接口会附带同比数据
+	CompareYear string `form:"compare_year"`
+
+	// 排序 (可选，各列表接口允许的字段不同)，order 为 asc (默认) 或 desc
+	Sort  string `form:"sort"`
+	Order string `form:"order"`
+	// 图斑明细返回的字段 (可选)，逗号分隔的 JSON 字段名，例如 tbbh,bhmj,thsheng
+	Fields string `form:"fields"`
+	// 已校验的 ORDER BY 子句，由 Service 根据 sort/order 生成，为空时使用各接口的默认排序
+	OrderBy string `form:"-" json:"-"`
+
+	// 游标分页 (图斑明细可选): 带 cursor 参数时改用游标分页并忽略 page，第一页传空值 (cursor=)，
+	// 之后传上一页返回的 next_cursor；skip_count=true 时不统计总数
+	Cursor    *string `form:"cursor"`
+	SkipCount bool    `form:"skip_count"`
+
+	// 当前用户的数据范围，由 handler 根据登录用户填充，不从 URL 读取
+	UserScope RegionScope `form:"-" json:"-"`
 }
 
 // AlertQueryRequest 预警接口专用请求参数
@@ -82,6 +173,13 @@ type AlertQueryRequest struct {
 	AlertArea float64 `form:"alert_area" binding:"required"` // 预警面积阈值
 	Page      int     `form:"page,default=1"`
 	PageSize  int     `form:"page_size,default=10"`
+	Sort      string  `form:"sort"`       // bhmj (默认) 或 tbbh
+	Order     string  `form:"order"`      // asc 或 desc，默认 desc
+	Cursor    *string `form:"cursor"`     // 游标分页，用法同 NatureQueryRequest.Cursor
+	SkipCount bool    `form:"skip_count"` // 游标分页时不统计总数
+
+	UserScope RegionScope `form:"-" json:"-"` // 当前用户的数据范围
+	OrderBy   string      `form:"-" json:"-"` // 已校验的 ORDER BY 子句
 }
 
 // ProtectedAreaStat 接口1的返回结构
@@ -89,6 +187,8 @@ type ProtectedAreaStat struct {
 	Name  string  `json:"name"`  // 保护地名称
 	Count int64   `json:"count"` // 图斑个数
 	Area  float64 `json:"area"`  // 面积
+
+	Compare *StatComparison `gorm:"-" json:"compare,omitempty"` // 指定 compare_year 时的同比
 }
 
 // SpotListItem 接口2专用：精简的图斑明细对象
@@ -107,6 +207,8 @@ type TransitionStat struct {
 	Area       float64 `json:"area"`        // 面积
 	CountRatio float64 `json:"count_ratio"` // 个数占比 (%)
 	AreaRatio  float64 `json:"area_ratio"`  // 面积占比 (%)
+
+	Compare *StatComparison `gorm:"-" json:"compare,omitempty"` // 指定 compare_year 时的同比
 }
 
 // AlertSpotItem 预警图斑返回项 (DTO)
@@ -116,3 +218,78 @@ type AlertSpotItem struct {
 	BHMJ    float64 `json:"bhmj"`    // 图斑面积
 	THSHENG string  `json:"thsheng"` // 所属省份
 }
+
+// TransitionMatrixRequest 地类流转矩阵查询参数
+type TransitionMatrixRequest struct {
+	NatureQueryRequest
+
+	Format string `form:"format"` // matrix (默认): 嵌套 JSON; sankey: 桑基图 nodes/links
+	Metric string `form:"metric"` // 桑基图连线的取值: count (默认) 或 area
+}
+
+// UnknownLandClass 前/后地类为空 (NULL 或空字符串) 时使用的名称
+const UnknownLandClass = "未知"
+
+// TransitionPairStat 一个 (前地类, 后地类) 组合的统计结果
+type TransitionPairStat struct {
+	QLX   string  `json:"qlx"`
+	HLX   string  `json:"hlx"`
+	Count int64   `json:"count"`
+	Area  float64 `json:"area"`
+}
+
+// TrendQueryRequest 趋势分析查询参数，所有参数均可选
+type TrendQueryRequest struct {
+	ChangeType    string `form:"change_type"`    // 变化地类，多个用逗号分隔；为空时为 资源损毁、恢复治理，all 表示全部
+	StartYear     string `form:"start_year"`     // 起始年份 (含)
+	EndYear       string `form:"end_year"`       // 结束年份 (含)
+	Metric        string `form:"metric"`         // count (默认) 或 area
+	Breakdown     string `form:"breakdown"`      // 细分维度: province, protected_type, batch；为空不细分
+	Scope         string `form:"scope"`          // 行政区范围: province, city, county (与 region_name 一起使用)
+	RegionName    string `form:"region_name"`    // 行政区名称
+	ProtectedType string `form:"protected_type"` // 保护地类型
+
+	UserScope RegionScope `form:"-" json:"-"` // 当前用户的数据范围
+}
+
+// BatchStatsRequest 分批次统计查询参数
+type BatchStatsRequest struct {
+	Year          string `form:"year" binding:"required"` // 年份 (必选)
+	ChangeType    string `form:"change_type"`             // 变化地类，多个用逗号分隔；为空或 all 表示全部
+	Scope         string `form:"scope"`                   // 行政区范围: province, city, county (与 region_name 一起使用)
+	RegionName    string `form:"region_name"`             // 行政区名称
+	ProtectedType string `form:"protected_type"`          // 保护地类型
+
+	UserScope RegionScope `form:"-" json:"-"` // 当前用户的数据范围
+}
+
+// TrendFilter 传给 Store 的趋势查询条件 (已经过 Service 校验和转换)
+type TrendFilter struct {
+	ChangeTypes   []string // 为空表示全部
+	StartYear     string
+	EndYear       string
+	GroupCol      string // 细分维度对应的列名，为空不细分
+	RegionCol     string // 行政区筛选列名
+	RegionName    string
+	ProtectedType string
+	UserScope     RegionScope
+}
+
+// StringValue 按数据库列名取字段值 (转换为字符串)，未知列返回空字符串
+func (d *NatureData) StringValue(column string) string {
+	for i, f := range NatureFields {
+		if f.Column == column {
+			return fmt.Sprint(d.Values()[i])
+		}
+	}
+	return ""
+}
+
+// SpotDetail 图斑详情: 完整记录加上派生信息
+type SpotDetail struct {
+	NatureData
+
+	BatchName          string `json:"batch_name"`           // 批次名称，例如 第一批次
+	ProtectedTypeLabel string `json:"protected_type_label"` // 保护地类型中文名，例如 国家级自然保护区
+	HasImage           bool   `json:"has_image"`            // 是否有图斑图片 (/api/spots/:tbbh/images)
+}
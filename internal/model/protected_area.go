@@ -0,0 +1,74 @@
+package model
+
+import "time"
+
+// ProtectedTypeMap 保护地类型中文名 (以及英文缩写本身) 到英文缩写的映射
+// nature_data.BHDLX 和 protected_area.type_code 存的是英文缩写；原报的 YBBHDLXBM 可能是缩写也可能是中文名
+var ProtectedTypeMap = map[string]string{
+	"NP":       "NP", // 允许直接传英文
+	"NR":       "NR",
+	"FP":       "FP",
+	"WP":       "WP",
+	"GP":       "GP",
+	"DP":       "DP",
+	"SH":       "SH",
+	"国家公园":     "NP",
+	"国家级自然保护区": "NR",
+	"森林公园":     "FP",
+	"湿地公园":     "WP",
+	"地质公园":     "GP",
+	"荒漠公园":     "DP",
+	"风景名胜区":    "SH",
+}
+
+// ProtectedArea 对应数据库表 protected_area (保护地名录)
+// 替代原先 service 中写死的保护地个数/总面积常量
+type ProtectedArea struct {
+	ID              uint      `gorm:"column:id;primaryKey" json:"id"`
+	Name            string    `gorm:"column:name;size:255;not null;uniqueIndex" json:"name"`   // 保护地名称 (与 nature_data.THBHDMC 对应)
+	TypeCode        string    `gorm:"column:type_code;size:8;not null;index" json:"type_code"` // 保护地类型缩写: NP, NR, FP ...
+	Level           string    `gorm:"column:level;size:32" json:"level"`                       // 级别: 国家级、省级 ...
+	Province        string    `gorm:"column:province;size:64;index" json:"province"`           // 所在省份
+	OfficialArea    float64   `gorm:"column:official_area" json:"official_area"`               // 批复面积 (公顷)
+	EstablishedDate string    `gorm:"column:established_date;size:10" json:"established_date"` // 设立日期 YYYY-MM-DD
+	ManagingAgency  string    `gorm:"column:managing_agency;size:255" json:"managing_agency"`  // 管理机构
+	CreatedAt       time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt       time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (ProtectedArea) TableName() string {
+	return "protected_area"
+}
+
+// ProtectedAreaQueryRequest 保护地名录列表查询参数
+type ProtectedAreaQueryRequest struct {
+	Name     string `form:"name"`      // 名称 (模糊匹配)
+	TypeCode string `form:"type_code"` // 保护地类型
+	Level    string `form:"level"`     // 级别
+	Province string `form:"province"`  // 省份
+
+	Page     int `form:"page,default=1"`
+	PageSize int `form:"page_size,default=10"`
+}
+
+// ProtectedAreaInput 新增/修改保护地的请求体
+type ProtectedAreaInput struct {
+	Name            string  `json:"name" binding:"required"`
+	TypeCode        string  `json:"type_code" binding:"required"`
+	Level           string  `json:"level"`
+	Province        string  `json:"province"`
+	OfficialArea    float64 `json:"official_area" binding:"gte=0"`
+	EstablishedDate string  `json:"established_date"` // YYYY-MM-DD，可为空
+	ManagingAgency  string  `json:"managing_agency"`
+}
+
+// OverviewQueryRequest 年度概况查询参数
+type OverviewQueryRequest struct {
+	Year          string `form:"year" binding:"required"` // 年份 (必选)
+	ProtectedType string `form:"protected_type"`          // 保护地类型 (可选)
+	Province      string `form:"province"`                // 省份 (可选)
+	CompareYear   string `form:"compare_year"`            // 对比年份 (可选)，返回同比数据
+
+	UserScope RegionScope `form:"-" json:"-"` // 当前用户的数据范围
+}
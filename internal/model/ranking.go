@@ -0,0 +1,23 @@
+package model
+
+// RankingRequest 排行榜查询参数
+type RankingRequest struct {
+	Year          string `form:"year" binding:"required"` // 年份 (必选)
+	By            string `form:"by"`                      // 排名对象: protected_area (默认), province, city, county
+	Metric        string `form:"metric"`                  // count (默认) 或 area
+	ChangeType    string `form:"change_type"`             // 变化地类，默认 资源损毁，all 表示全部
+	Top           int    `form:"top,default=10"`          // 返回前 N 名
+	Scope         string `form:"scope"`                   // 行政区范围: province, city, county (与 region_name 一起使用)
+	RegionName    string `form:"region_name"`             // 行政区名称
+	ProtectedType string `form:"protected_type"`          // 保护地类型
+
+	UserScope RegionScope `form:"-" json:"-"` // 当前用户的数据范围
+}
+
+// RankingItem 排行榜中的一项
+type RankingItem struct {
+	Rank  int     `gorm:"-" json:"rank"`
+	Name  string  `json:"name"`
+	Count int64   `json:"count"`
+	Area  float64 `json:"area"`
+}
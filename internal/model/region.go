@@ -0,0 +1,44 @@
+package model
+
+// RegionTreeRequest 行政区树查询参数
+type RegionTreeRequest struct {
+	Year          string `form:"year" binding:"required"` // 年份 (必选)
+	Depth         int    `form:"depth,default=3"`         // 层级: 1=省, 2=省-市, 3=省-市-县
+	Province      string `form:"province"`                // 只看某个省 (可选)
+	ChangeType    string `form:"change_type"`             // 变化地类 (可选)
+	ProtectedType string `form:"protected_type"`          // 保护地类型 (可选)
+
+	UserScope RegionScope `form:"-" json:"-"` // 当前用户的数据范围
+}
+
+// RegionDictRequest 行政区字典查询参数
+type RegionDictRequest struct {
+	Year  string `form:"year"`            // 只列出该年份有图斑的行政区，为空表示全部年份
+	Depth int    `form:"depth,default=3"` // 层级: 1=省, 2=省-市, 3=省-市-县
+
+	UserScope RegionScope `form:"-" json:"-"` // 当前用户的数据范围
+}
+
+// RegionRow 按省/市/县分组的一行查询结果，层级不足时较低层级为空
+type RegionRow struct {
+	Province string
+	City     string
+	County   string
+	Count    int64
+	Area     float64
+}
+
+// RegionNode 行政区树节点，count/area 为该行政区 (含全部下级) 的合计
+type RegionNode struct {
+	Name     string        `json:"name"`
+	Level    string        `json:"level"` // province, city, county
+	Count    int64         `json:"count"`
+	Area     float64       `json:"area"`
+	Children []*RegionNode `json:"children,omitempty"`
+}
+
+// RegionDictNode 行政区字典节点 (只有名称)
+type RegionDictNode struct {
+	Name     string            `json:"name"`
+	Children []*RegionDictNode `json:"children,omitempty"`
+}
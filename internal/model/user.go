@@ -0,0 +1,90 @@
+package model
+
+import "time"
+
+// 用户角色: 角色决定了用户可以访问的数据范围
+const (
+	RoleAdmin    = "admin"    // 管理员: 全国数据 + 用户、名录、导入等管理操作
+	RoleNational = "national" // 全国只读
+	RoleProvince = "province" // 省级用户: 只能访问本省 (THSHENG) 数据
+	RoleCity     = "city"     // 市级用户: 只能访问本市 (THSHENG + THSHI) 数据
+	RoleCounty   = "county"   // 县级用户: 只能访问本县 (THSHENG + THSHI + THXIAN) 数据
+)
+
+// User 对应数据库表 sys_user
+type User struct {
+	ID           uint      `gorm:"column:id;primaryKey" json:"id"`
+	Username     string    `gorm:"column:username;size:64;not null;uniqueIndex" json:"username"`
+	PasswordHash string    `gorm:"column:password_hash;size:255;not null" json:"-"`
+	Role         string    `gorm:"column:role;size:16;not null" json:"role"`
+	Province     string    `gorm:"column:province;size:64" json:"province"`    // 省 (对应 THSHENG)
+	City         string    `gorm:"column:city;size:64" json:"city"`            // 市 (对应 THSHI)
+	County       string    `gorm:"column:county;size:64" json:"county"`        // 县 (对应 THXIAN)
+	APIKeyHash   string    `gorm:"column:api_key_hash;size:64;index" json:"-"` // API Key 的 SHA-256，明文只在生成时返回一次
+	Disabled     bool      `gorm:"column:disabled" json:"disabled"`
+	CreatedAt    time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt    time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
+
+// TableName 指定表名 (user 是 MySQL 保留字)
+func (User) TableName() string {
+	return "sys_user"
+}
+
+// Scope 返回用户的数据范围，管理员和全国用户不受限制
+func (u *User) Scope() RegionScope {
+	switch u.Role {
+	case RoleProvince:
+		return RegionScope{Province: u.Province}
+	case RoleCity:
+		return RegionScope{Province: u.Province, City: u.City}
+	case RoleCounty:
+		return RegionScope{Province: u.Province, City: u.City, County: u.County}
+	default:
+		return RegionScope{}
+	}
+}
+
+// RegionScope 数据访问范围，空字段表示该级不限制
+// 由认证中间件根据当前用户生成，Store 层统一追加到查询条件中
+type RegionScope struct {
+	Province string
+	City     string
+	County   string
+}
+
+// IsZero 是否为不受限制的范围
+func (s RegionScope) IsZero() bool {
+	return s.Province == "" && s.City == "" && s.County == ""
+}
+
+// Covers 判断某个行政区 (省、市、县) 是否在范围内
+func (s RegionScope) Covers(province, city, county string) bool {
+	return (s.Province == "" || s.Province == province) &&
+		(s.City == "" || s.City == city) &&
+		(s.County == "" || s.County == county)
+}
+
+// Operator 执行写操作的用户，用于审计记录；认证关闭时 UserID 为 0
+type Operator struct {
+	UserID   uint
+	Username string
+	IP       string
+}
+
+// LoginRequest 登录请求体
+type LoginRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// UserInput 新增/修改用户的请求体
+type UserInput struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password"` // 新增时必填；修改时为空表示不修改
+	Role     string `json:"role" binding:"required"`
+	Province string `json:"province"`
+	City     string `json:"city"`
+	County   string `json:"county"`
+	Disabled bool   `json:"disabled"`
+}
@@ -2,42 +2,166 @@ package router
 
 import (
 	"ProtectedArea/internal/handler"
+	"ProtectedArea/internal/middleware"
+	"ProtectedArea/internal/model"
+	"ProtectedArea/pkg/errcode"
+	"ProtectedArea/pkg/response"
 	"github.com/gin-gonic/gin"
 )
 
+// Handlers 汇总所有 handler，方便在 main 中统一注入
+type Handlers struct {
+	Nature        *handler.NatureHandler
+	ProtectedArea *handler.ProtectedAreaHandler
+	Import        *handler.ImportHandler
+	Auth          *handler.AuthHandler
+	User          *handler.UserHandler
+	Batch         *handler.BatchHandler
+	Image         *handler.ImageHandler
+	Pack          *handler.PackHandler
+}
+
 // InitRouter 初始化路由
-func InitRouter(natureHandler *handler.NatureHandler) *gin.Engine {
-	r := gin.Default()
+// mode 为 gin 运行模式 (debug, release, test)，来自配置 server.mode
+// auth 为认证中间件，除登录接口外的所有 /api 接口都需要经过它
+func InitRouter(mode string, h Handlers, auth gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(mode)
+	r := gin.New()
 
 	// 可以在这里加跨域中间件等
+	r.Use(middleware.RequestID(), gin.Logger())
+	// panic 时也返回统一格式的 500 响应
+	r.Use(gin.CustomRecovery(func(c *gin.Context, recovered any) {
+		response.Error(c, errcode.Internal)
+	}))
+	r.NoRoute(func(c *gin.Context) {
+		response.Error(c, errcode.NotFound.WithMessage("接口不存在"))
+	})
+
+	natureHandler := h.Nature
 
-	api := r.Group("/api")
+	// 登录接口不需要认证: POST /api/auth/login
+	r.POST("/api/auth/login", h.Auth.Login)
+
+	api := r.Group("/api", auth)
+	// admin 只允许管理员访问 (写操作和用户管理)
+	admin := api.Group("", middleware.RequireRole(model.RoleAdmin))
 	{
+		// 当前登录用户: /api/auth/me
+		api.GET("/auth/me", h.Auth.Me)
+
+		// 0. 趋势分析: /api/stats/trend?change_type=资源损毁,恢复治理&start_year=2020&end_year=2024&metric=count&breakdown=province
 		api.GET("/stats/trend", natureHandler.GetTrendStats)
 
-		// 1. 年度概况: /api/stats/overview?year=2023
+		// 1. 年度概况: /api/stats/overview?year=2023&protected_type=NR&province=河北省
+		// 年度概况、行政区、保护地、流向分析接口支持 compare_year=2022 返回同比 (增减量、增减率)
 		api.GET("/stats/overview", natureHandler.GetYearlyOverview)
 
+		// 2-7 中的分批次损毁、行政区、保护地、图斑明细、流向分析、大图斑预警接口
+		// 支持 format=csv|xlsx 导出全部数据，例如 /api/stats/spot-list?year=2024&scope=province&format=xlsx
+
 		// 2. 分批次损毁统计: /api/stats/damage-batch?year=2023
 		api.GET("/stats/damage-batch", natureHandler.GetDamageBatchStats)
 
+		// 2.1 分批次统计 (任意变化地类): /api/stats/batch?year=2024&change_type=资源损毁,恢复治理
+		api.GET("/stats/batch", natureHandler.GetBatchStats)
+
 		// 3. 行政区划统计: /api/stats/region?year=2025&scope=province&name=河北省
 		api.GET("/stats/region", natureHandler.GetRegionStats)
 
-		// 4. 保护地统计
+		// 3.1 行政区树 (省 → 市 → 县，带合计): /api/regions/tree?year=2025&depth=3
+		api.GET("/regions/tree", natureHandler.GetRegionTree)
+		// 3.2 行政区字典 (级联选择): /api/regions?year=2025&depth=2
+		api.GET("/regions", natureHandler.GetRegionDict)
+
+		// 4-7 及空间查询的分页列表支持 sort/order 排序，例如 sort=area&order=desc
+
+		// 4. 保护地统计: sort 可选 name, count, area
 		api.GET("/stats/protected-area", natureHandler.GetProtectedAreaStats)
 
-		// 5. 图斑明细
+		// 4.1 排行榜 (Top N): /api/stats/ranking?year=2024&by=province&metric=area&top=10
+		api.GET("/stats/ranking", natureHandler.GetRanking)
+
+		// 5. 图斑明细: sort 可选 tbbh, bhmj (area)；fields=tbbh,bhmj,thsheng 只返回指定字段
+		// 游标分页: 第一页 ?year=2024&cursor=&page_size=100，之后传上一页返回的 next_cursor (skip_count=true 不统计总数)
 		api.GET("/stats/spot-list", natureHandler.GetSpotList)
 
 		// 6. 流向分析 (饼图)
 		api.GET("/stats/transition", natureHandler.GetTransitionStats)
 
-		// 7. 大图斑预警 : /api/stats/alert/large-spots?year=2022&alert_area=500&page=1
+		// 6.1 流转矩阵 (前地类 × 后地类): /api/stats/transition-matrix?year=2024&scope=province&format=sankey&metric=area
+		api.GET("/stats/transition-matrix", natureHandler.GetTransitionMatrix)
+
+		// 6.2 重复变化报告 (按保护地汇总): /api/stats/repeat-changes?year=2024&scope=province&region_name=河北省
+		api.GET("/stats/repeat-changes", natureHandler.GetRepeatChanges)
+		// 重复变化图斑明细 (分页): /api/stats/repeat-changes/spots?year=2024&scope=province&name=某保护地&page=1
+		api.GET("/stats/repeat-changes/spots", natureHandler.GetRepeatChangeSpots)
+
+		// 6.3 核实/原报差异 (上报质量审计): /api/stats/attribution-diff?year=2024&scope=province&group_by=province
+		api.GET("/stats/attribution-diff", natureHandler.GetAttributionDiffStats)
+		api.GET("/stats/attribution-diff/spots", natureHandler.GetAttributionDiffSpots)
+
+		// 7. 大图斑预警 : /api/stats/alert/large-spots?year=2022&alert_area=500&page=1 (同样支持 cursor 游标分页)
 		api.GET("/stats/alert/large-spots", natureHandler.GetLargeSpots)
 
 		// 8. 获取图斑图片: /api/image?tbbh=110109202202NR001
-		api.GET("/image", natureHandler.GetPatchImage)
+		// 图片接口支持 width/height/quality/format=jpeg|png|webp 返回缩略图，例如 /api/images/12?width=320&format=webp
+		api.GET("/image", h.Image.GetPatchImage)
+
+		// 图斑点位 GeoJSON: /api/spots.geojson?year=2024&scope=province&bbox=114,36,120,42
+		api.GET("/spots.geojson", natureHandler.GetSpotsGeoJSON)
+		// 离线资料包 (spots.csv + spots.geojson + images/): /api/spots.zip?year=2024&scope=county&region_name=涞水县&change_type=资源损毁
+		api.GET("/spots.zip", h.Pack.GetSpotPack)
+
+		// 空间查询: /api/spots/bbox?bbox=minx,miny,maxx,maxy 和 /api/spots/radius?x=&y=&radius=米
+		api.GET("/spots/bbox", natureHandler.GetSpotsInBBox)
+		api.GET("/spots/radius", natureHandler.GetSpotsWithinRadius)
+
+		// 网格聚合 (地图缩小时显示聚合点): /api/spots/clusters?bbox=73,18,135,54&zoom=5
+		api.GET("/spots/clusters", natureHandler.GetSpotClusters)
+
+		// 图斑详情 (完整记录): /api/spots/110109202202NR001
+		api.GET("/spots/:tbbh", natureHandler.GetSpotDetail)
+		// 图斑演变链 (沿上期图斑编号追溯): /api/spots/110109202202NR001/lineage
+		api.GET("/spots/:tbbh/lineage", natureHandler.GetSpotLineage)
+
+		// 8.1 图斑的全部图片 (变化前/变化后/外业照片): /api/spots/110109202202NR001/images
+		api.GET("/spots/:tbbh/images", h.Image.ListSpotImages)
+		// 获取单张图片: /api/images/12
+		api.GET("/images/:id", h.Image.GetImage)
+		// 重新扫描图片目录，更新图片索引: POST /api/images/rescan
+		admin.POST("/images/rescan", h.Image.Rescan)
+		// 上传/替换/删除图片 (multipart: file, role=before|after|field, 可选 seq)，每次变更都会写入变更记录
+		admin.POST("/spots/:tbbh/images", h.Image.Upload)
+		admin.PUT("/images/:id", h.Image.Replace)
+		admin.DELETE("/images/:id", h.Image.Delete)
+		// 图片变更记录: /api/images/audit?tbbh=110109202202NR001
+		admin.GET("/images/audit", h.Image.ListAudits)
+		// 图片完整性检查 (缺图/孤立文件/损坏/重复): /api/images/check?year=2024&deep=true&format=csv&view=missing
+		admin.GET("/images/check", h.Image.CheckImages)
+
+		// 9. 保护地名录: /api/protected-areas?type_code=NR&province=河北省
+		api.GET("/protected-areas", h.ProtectedArea.List)
+		api.GET("/protected-areas/:id", h.ProtectedArea.Get)
+		admin.POST("/protected-areas", h.ProtectedArea.Create)
+		admin.PUT("/protected-areas/:id", h.ProtectedArea.Update)
+		admin.DELETE("/protected-areas/:id", h.ProtectedArea.Delete)
+
+		// 10. 图斑批量导入 (CSV/XLSX): POST /api/import/spots
+		admin.POST("/import/spots", h.Import.ImportSpots)
+
+		// 11. 批次登记表 (PC 归类规则): /api/batches?year=2024
+		api.GET("/batches", h.Batch.List)
+		admin.POST("/batches", h.Batch.Create)
+		admin.PUT("/batches/:id", h.Batch.Update)
+		admin.DELETE("/batches/:id", h.Batch.Delete)
+
+		// 12. 用户管理: /api/users
+		admin.GET("/users", h.User.List)
+		admin.POST("/users", h.User.Create)
+		admin.PUT("/users/:id", h.User.Update)
+		admin.DELETE("/users/:id", h.User.Delete)
+		admin.POST("/users/:id/api-key", h.User.ResetAPIKey)
 	}
 
 	return r
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"ProtectedArea/internal/model"
+	"slices"
+	"strings"
+)
+
+// attributionGroupColumns 差异统计的分组维度 -> 列名
+var attributionGroupColumns = map[string]string{
+	"province":       "THSHENG",
+	"protected_area": "THBHDMC",
+}
+
+// GetAttributionDiffStats 核实值 (TH*) 与原报值 (YB*) 差异统计，用于评估上报数据质量
+func (s *natureService) GetAttributionDiffStats(req model.AttributionDiffRequest) (map[string]interface{}, error) {
+	// 1. 校验分组维度
+	if req.GroupBy == "" {
+		req.GroupBy = "province"
+	}
+	groupCol, ok := attributionGroupColumns[req.GroupBy]
+	if !ok {
+		return nil, newValidationError("无效的分组维度(group_by): %s (可选 province, protected_area)", req.GroupBy)
+	}
+
+	// 2. 分组统计
+	stats, err := s.store.GetAttributionDiffStats(req.NatureQueryRequest, groupCol)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. 计算占比，同时累加总计
+	summary := model.AttributionDiffStat{Name: "合计"}
+	for i := range stats {
+		item := &stats[i]
+		if item.Name == "" {
+			item.Name = "未知"
+		}
+		item.MismatchRatio = ratio(float64(item.Mismatched), float64(item.Total))
+
+		summary.Total += item.Total
+		summary.Mismatched += item.Mismatched
+		summary.ProtectedAreaDiff += item.ProtectedAreaDiff
+		summary.ProtectedTypeDiff += item.ProtectedTypeDiff
+		summary.ProvinceDiff += item.ProvinceDiff
+		summary.CityDiff += item.CityDiff
+		summary.CountyDiff += item.CountyDiff
+	}
+	summary.MismatchRatio = ratio(float64(summary.Mismatched), float64(summary.Total))
+
+	return map[string]interface{}{
+		"group_by": req.GroupBy,
+		"summary":  summary,
+		"list":     stats,
+	}, nil
+}
+
+// GetAttributionDiffSpots 核实值与原报值不一致的图斑明细 (带分页)
+func (s *natureService) GetAttributionDiffSpots(req model.AttributionDiffRequest) (map[string]interface{}, error) {
+	// 1. 确定要核对的字段
+	fields := model.AttributionFields
+	if req.Field != "" {
+		fields = nil
+		for _, f := range model.AttributionFields {
+			if f.Key == req.Field {
+				fields = []model.AttributionField{f}
+				break
+			}
+		}
+		if fields == nil {
+			return nil, newValidationError("无效的核对字段(field): %s", req.Field)
+		}
+	}
+
+	orderBy, err := buildOrderBy(req.Sort, req.Order, spotSortColumns, "TBBH")
+	if err != nil {
+		return nil, err
+	}
+	req.OrderBy = orderBy
+
+	// 2. 查询
+	spots, total, err := s.store.GetAttributionDiffSpots(req.NatureQueryRequest, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. 列出每个图斑具体哪些字段不一致 (由数据库判断，与筛选条件的比较规则一致)
+	list := make([]model.AttributionDiffItem, len(spots))
+	for i := range spots {
+		spot := &spots[i]
+		item := model.AttributionDiffItem{
+			TBBH:    spot.TBBH,
+			BHDL:    spot.BHDL,
+			BHMJ:    spot.BHMJ,
+			THBHDMC: spot.THBHDMC,
+			THSHENG: spot.THSHENG,
+			Diffs:   []model.AttributionDiffValue{},
+		}
+		diffs := strings.Split(spot.DiffFields, ",")
+		for _, f := range fields {
+			if slices.Contains(diffs, f.Key) {
+				item.Diffs = append(item.Diffs, model.AttributionDiffValue{Field: f.Key, Verified: spot.StringValue(f.TH), Reported: spot.StringValue(f.YB)})
+			}
+		}
+		list[i] = item
+	}
+
+	return buildPagedResponse(list, total, req.Page, req.PageSize), nil
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"ProtectedArea/internal/model"
+	"ProtectedArea/internal/store"
+	"ProtectedArea/pkg/errcode"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// apiKeyPrefix API Key 的固定前缀，便于在日志和配置中识别
+const apiKeyPrefix = "pak_"
+
+type AuthService interface {
+	// Login 校验用户名密码，返回 JWT 及其过期时间
+	Login(req model.LoginRequest) (string, time.Time, *model.User, error)
+	// AuthenticateToken 校验 JWT 并返回对应的 (未禁用的) 用户
+	AuthenticateToken(token string) (*model.User, error)
+	// AuthenticateAPIKey 校验 API Key 并返回对应的 (未禁用的) 用户
+	AuthenticateAPIKey(key string) (*model.User, error)
+	// EnsureAdmin 用户表为空时创建初始管理员
+	EnsureAdmin(username, password string) error
+}
+
+type authService struct {
+	store    store.UserStore
+	secret   []byte
+	tokenTTL time.Duration
+}
+
+func NewAuthService(s store.UserStore, jwtSecret string, tokenTTL time.Duration) AuthService {
+	return &authService{store: s, secret: []byte(jwtSecret), tokenTTL: tokenTTL}
+}
+
+func (s *authService) Login(req model.LoginRequest) (string, time.Time, *model.User, error) {
+	u, err := s.store.GetByUsername(req.Username)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", time.Time{}, nil, errcode.InvalidCredentials
+	}
+	if err != nil {
+		return "", time.Time{}, nil, err
+	}
+	if bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(req.Password)) != nil {
+		return "", time.Time{}, nil, errcode.InvalidCredentials
+	}
+	if u.Disabled {
+		return "", time.Time{}, nil, errcode.Forbidden.WithMessage("账号已被禁用")
+	}
+
+	expiresAt := time.Now().Add(s.tokenTTL)
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		Subject:   strconv.FormatUint(uint64(u.ID), 10),
+		IssuedAt:  jwt.NewNumericDate(time.Now()),
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+	})
+	signed, err := token.SignedString(s.secret)
+	if err != nil {
+		return "", time.Time{}, nil, fmt.Errorf("签发令牌失败: %w", err)
+	}
+	return signed, expiresAt, u, nil
+}
+
+// AuthenticateToken 令牌中只保存用户 ID，每次请求重新加载用户，
+// 这样禁用账号或调整数据范围后立即生效
+func (s *authService) AuthenticateToken(tokenString string) (*model.User, error) {
+	var claims jwt.RegisteredClaims
+	_, err := jwt.ParseWithClaims(tokenString, &claims, func(t *jwt.Token) (interface{}, error) {
+		return s.secret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil {
+		return nil, errcode.Unauthorized
+	}
+
+	id, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		return nil, errcode.Unauthorized
+	}
+	return s.loadActiveUser(s.store.GetByID(uint(id)))
+}
+
+func (s *authService) AuthenticateAPIKey(key string) (*model.User, error) {
+	return s.loadActiveUser(s.store.GetByAPIKeyHash(hashAPIKey(key)))
+}
+
+// loadActiveUser 统一处理用户不存在和已禁用的情况
+func (s *authService) loadActiveUser(u *model.User, err error) (*model.User, error) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errcode.Unauthorized
+	}
+	if err != nil {
+		return nil, err
+	}
+	if u.Disabled {
+		return nil, errcode.Forbidden.WithMessage("账号已被禁用")
+	}
+	return u, nil
+}
+
+func (s *authService) EnsureAdmin(username, password string) error {
+	if username == "" || password == "" {
+		return nil
+	}
+	total, err := s.store.Count()
+	if err != nil || total > 0 {
+		return err
+	}
+
+	hash, err := hashPassword(password)
+	if err != nil {
+		return err
+	}
+	if err := s.store.Create(&model.User{Username: username, PasswordHash: hash, Role: model.RoleAdmin}); err != nil {
+		return fmt.Errorf("创建初始管理员失败: %w", err)
+	}
+	log.Printf("已创建初始管理员账号: %s，请尽快修改密码", username)
+	return nil
+}
+
+// hashPassword bcrypt 加密密码
+func hashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("密码加密失败: %w", err)
+	}
+	return string(hash), nil
+}
+
+// newAPIKey 生成新的 API Key，返回明文和用于存储的哈希
+func newAPIKey() (string, string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", fmt.Errorf("生成 API Key 失败: %w", err)
+	}
+	key := apiKeyPrefix + hex.EncodeToString(b)
+	return key, hashAPIKey(key), nil
+}
+
+// hashAPIKey API Key 本身是高熵随机串，用 SHA-256 即可，便于按哈希直接查询
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,253 @@
+package service
+
+import (
+	"ProtectedArea/internal/model"
+	"ProtectedArea/internal/store"
+	"ProtectedArea/pkg/errcode"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type BatchService interface {
+	// List 返回批次规则，指定年份时只返回适用于该年份的规则 (按匹配顺序)
+	List(req model.BatchQueryRequest) ([]model.Batch, error)
+	Create(input model.BatchInput) (*model.Batch, error)
+	Update(id uint, input model.BatchInput) (*model.Batch, error)
+	Delete(id uint) error
+
+	// EnsureDefaults 批次表为空时写入默认规则，并把规则加载到内存
+	EnsureDefaults() error
+	// Resolve 根据 PC 和年份返回批次名称及其排序值 (越小越靠前)
+	// 不匹配任何规则时返回 "其他批次"，PC 为空时返回 "未知批次"，两者都排在最后
+	Resolve(pc, year string) (string, int)
+}
+
+// batchCacheTTL 规则缓存的有效期: 命令行导入或其它实例直接修改批次表后，最迟在这段时间后生效
+const batchCacheTTL = time.Minute
+
+// batchRule 编译好的批次规则
+type batchRule struct {
+	batch model.Batch
+	re    *regexp.Regexp
+}
+
+type batchService struct {
+	store store.BatchStore
+
+	// 规则缓存: 每次按 PC 归类都要匹配，不能每次查库；增删改后立即重新加载，
+	// 其它进程的修改在缓存超过 batchCacheTTL 后由 Resolve 重新加载
+	mu       sync.RWMutex
+	rules    []batchRule
+	loadedAt time.Time
+
+	refreshing sync.Mutex // 同一时间只有一个 goroutine 刷新过期的缓存
+}
+
+func NewBatchService(s store.BatchStore) BatchService {
+	return &batchService{store: s}
+}
+
+func (s *batchService) List(req model.BatchQueryRequest) ([]model.Batch, error) {
+	batches, err := s.store.List()
+	if err != nil {
+		return nil, err
+	}
+	if req.Year == "" {
+		return batches, nil
+	}
+
+	// 只保留适用于该年份的规则，并按匹配顺序 (指定年份的规则优先) 排列
+	result := []model.Batch{}
+	for _, b := range batches {
+		if b.Year == "" || b.Year == req.Year {
+			result = append(result, b)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Year != "" && result[j].Year == ""
+	})
+	return result, nil
+}
+
+func (s *batchService) Create(input model.BatchInput) (*model.Batch, error) {
+	if err := validateBatchInput(input); err != nil {
+		return nil, err
+	}
+
+	b := &model.Batch{}
+	applyBatchInput(b, input)
+	if err := s.store.Create(b); err != nil {
+		return nil, err
+	}
+	return b, s.reload()
+}
+
+func (s *batchService) Update(id uint, input model.BatchInput) (*model.Batch, error) {
+	if err := validateBatchInput(input); err != nil {
+		return nil, err
+	}
+
+	b, err := s.store.GetByID(id)
+	if err != nil {
+		return nil, translateBatchError(err)
+	}
+	applyBatchInput(b, input)
+	if err := s.store.Update(b); err != nil {
+		return nil, err
+	}
+	return b, s.reload()
+}
+
+func (s *batchService) Delete(id uint) error {
+	if err := s.store.Delete(id); err != nil {
+		return translateBatchError(err)
+	}
+	return s.reload()
+}
+
+func (s *batchService) EnsureDefaults() error {
+	total, err := s.store.Count()
+	if err != nil {
+		return err
+	}
+	if total == 0 {
+		defaults := make([]model.Batch, len(model.DefaultBatches))
+		copy(defaults, model.DefaultBatches)
+		if err := s.store.CreateInBatches(defaults); err != nil {
+			return fmt.Errorf("写入默认批次失败: %w", err)
+		}
+		log.Printf("已写入 %d 条默认批次规则", len(defaults))
+	}
+	return s.reload()
+}
+
+// reload 从数据库重新加载规则，正则无法编译的规则 (例如直接改库写入的) 记录日志后跳过
+func (s *batchService) reload() error {
+	batches, err := s.store.List()
+	if err != nil {
+		return fmt.Errorf("加载批次规则失败: %w", err)
+	}
+
+	rules := make([]batchRule, 0, len(batches))
+	for _, b := range batches {
+		re, err := regexp.Compile(b.Pattern)
+		if err != nil {
+			log.Printf("批次规则 %d (%s) 的正则表达式无效，已忽略: %v", b.ID, b.Name, err)
+			continue
+		}
+		rules = append(rules, batchRule{batch: b, re: re})
+	}
+
+	s.mu.Lock()
+	s.rules = rules
+	s.loadedAt = time.Now()
+	s.mu.Unlock()
+	return nil
+}
+
+// refreshIfStale 缓存过期时重新加载规则；其它 goroutine 正在加载时直接使用旧规则
+// 加载失败时保留旧规则，下一个有效期后再重试
+func (s *batchService) refreshIfStale() {
+	if !s.isStale() || !s.refreshing.TryLock() {
+		return
+	}
+	defer s.refreshing.Unlock()
+	// 等锁期间可能已经被其它 goroutine 刷新过
+	if !s.isStale() {
+		return
+	}
+	if err := s.reload(); err != nil {
+		log.Printf("刷新批次规则失败，继续使用旧规则: %v", err)
+		s.mu.Lock()
+		s.loadedAt = time.Now()
+		s.mu.Unlock()
+	}
+}
+
+// isStale 规则缓存是否已超过有效期 (从未加载过也视为过期)
+func (s *batchService) isStale() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return time.Since(s.loadedAt) > batchCacheTTL
+}
+
+func (s *batchService) Resolve(pc, year string) (string, int) {
+	pc = strings.TrimSpace(pc)
+	if pc == "" {
+		return model.BatchNameUnknown, math.MaxInt
+	}
+
+	s.refreshIfStale()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	// 1. 先匹配指定了该年份的规则
+	for _, r := range s.rules {
+		if r.batch.Year != "" && r.batch.Year == year && r.re.MatchString(pc) {
+			return r.batch.Name, r.batch.SortOrder
+		}
+	}
+	// 2. 再匹配不限年份的规则
+	for _, r := range s.rules {
+		if r.batch.Year == "" && r.re.MatchString(pc) {
+			return r.batch.Name, r.batch.SortOrder
+		}
+	}
+	return model.BatchNameOther, math.MaxInt - 1
+}
+
+// validateBatchInput 业务校验 (binding 标签无法覆盖的部分)
+func validateBatchInput(input model.BatchInput) error {
+	if strings.TrimSpace(input.Name) == "" {
+		return newValidationError("批次名称(name)不能为空")
+	}
+	if _, err := regexp.Compile(input.Pattern); err != nil {
+		return newValidationError("匹配规则(pattern)不是合法的正则表达式: %v", err)
+	}
+	if input.Year != "" && !yearPattern.MatchString(input.Year) {
+		return newValidationError("年份(year)必须是四位数字: %s", input.Year)
+	}
+	for _, d := range []struct{ name, value string }{
+		{"period_start", input.PeriodStart},
+		{"period_end", input.PeriodEnd},
+	} {
+		if d.value == "" {
+			continue
+		}
+		if _, err := time.Parse(time.DateOnly, d.value); err != nil {
+			return newValidationError("%s 格式应为 YYYY-MM-DD: %s", d.name, d.value)
+		}
+	}
+	if input.PeriodStart != "" && input.PeriodEnd != "" && input.PeriodStart > input.PeriodEnd {
+		return newValidationError("影像时段开始日期不能晚于结束日期")
+	}
+	return nil
+}
+
+// applyBatchInput 把请求体的字段复制到实体上
+func applyBatchInput(b *model.Batch, input model.BatchInput) {
+	b.Name = strings.TrimSpace(input.Name)
+	b.Pattern = input.Pattern
+	b.Year = input.Year
+	b.PeriodStart = input.PeriodStart
+	b.PeriodEnd = input.PeriodEnd
+	b.SortOrder = input.SortOrder
+	b.Remark = input.Remark
+}
+
+// translateBatchError 把数据库错误转换为业务错误
+func translateBatchError(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errcode.BatchNotFound
+	}
+	return err
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"ProtectedArea/internal/model"
+	"sort"
+)
+
+// BatchSeries 一个变化地类在各批次上的统计，Count/Area 与 batches 一一对应
+type BatchSeries struct {
+	ChangeType string    `json:"change_type"`
+	Count      []int64   `json:"count"`
+	Area       []float64 `json:"area"`
+}
+
+// GetBatchStats 分批次统计: 指定年份内各变化地类在每个批次的个数和面积
+// 返回格式: {"year": "2024", "batches": ["第一批次", ...], "series": [{"change_type": "资源损毁", "count": [...], "area": [...]}]}
+func (s *natureService) GetBatchStats(req model.BatchStatsRequest) (map[string]interface{}, error) {
+	// 1. 参数校验和转换 (复用趋势分析的查询条件，起止年份相同，按 PC 细分)
+	if !yearPattern.MatchString(req.Year) {
+		return nil, newValidationError("年份必须是四位数字: %s", req.Year)
+	}
+	filter := model.TrendFilter{
+		ChangeTypes:   parseChangeTypes(req.ChangeType),
+		StartYear:     req.Year,
+		EndYear:       req.Year,
+		GroupCol:      "PC",
+		ProtectedType: req.ProtectedType,
+		RegionName:    req.RegionName,
+		UserScope:     req.UserScope,
+	}
+	if req.RegionName != "" {
+		col, ok := scopeColumns[req.Scope]
+		if !ok {
+			return nil, newValidationError("无效的查询范围(scope): %s", req.Scope)
+		}
+		filter.RegionCol = col
+	}
+
+	// 2. 查询并聚合
+	batches, series, err := s.collectBatchStats(filter, req.Year)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"year":    req.Year,
+		"batches": batches,
+		"series":  series,
+	}, nil
+}
+
+// collectBatchStats 把原始 PC 归类为批次名称后按 (变化地类, 批次) 聚合
+// 批次按登记表中的顺序排列，变化地类按名称排序；某变化地类在某批次没有数据时为 0
+func (s *natureService) collectBatchStats(f model.TrendFilter, year string) ([]string, []BatchSeries, error) {
+	rawStats, err := s.store.GetTrendStats(f)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// 1. 归类批次，多个原始 PC 可能对应同一个批次，例如 "202301" 和 "2023-01"
+	type key struct{ changeType, batch string }
+	counts := make(map[key]int64)
+	areas := make(map[key]float64)
+	orders := make(map[string]int) // 批次名称 -> 排序值
+	changeTypes := make(map[string]bool)
+	for _, item := range rawStats {
+		name, order := s.batches.Resolve(item.GroupName, year)
+		orders[name] = order
+		changeTypes[item.BHDL] = true
+
+		k := key{changeType: item.BHDL, batch: name}
+		counts[k] += item.Count
+		areas[k] += item.Area
+	}
+
+	// 2. 排序批次和变化地类
+	batches := make([]string, 0, len(orders))
+	for name := range orders {
+		batches = append(batches, name)
+	}
+	sort.Slice(batches, func(i, j int) bool {
+		if orders[batches[i]] != orders[batches[j]] {
+			return orders[batches[i]] < orders[batches[j]]
+		}
+		return batches[i] < batches[j]
+	})
+	types := make([]string, 0, len(changeTypes))
+	for t := range changeTypes {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+
+	// 3. 生成序列
+	series := make([]BatchSeries, len(types))
+	for i, t := range types {
+		series[i] = BatchSeries{ChangeType: t, Count: make([]int64, len(batches)), Area: make([]float64, len(batches))}
+		for j, b := range batches {
+			series[i].Count[j] = counts[key{t, b}]
+			series[i].Area[j] = areas[key{t, b}]
+		}
+	}
+	return batches, series, nil
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"ProtectedArea/internal/model"
+	"ProtectedArea/internal/store"
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+// fakeBatchStore 返回内存中的批次规则，并记录加载次数
+type fakeBatchStore struct {
+	store.BatchStore
+	batches []model.Batch
+	err     error
+	lists   int
+}
+
+func (f *fakeBatchStore) List() ([]model.Batch, error) {
+	f.lists++
+	return f.batches, f.err
+}
+
+func TestBatchResolve(t *testing.T) {
+	fake := &fakeBatchStore{batches: []model.Batch{
+		{ID: 1, Name: "第一批次", Pattern: "01$", SortOrder: 1},
+		{ID: 2, Name: "第二批次", Pattern: "02$", SortOrder: 2},
+		{ID: 3, Name: "2024年春季", Pattern: "01$", Year: "2024", SortOrder: 10},
+		{ID: 4, Name: "无效规则", Pattern: "(", SortOrder: 0},
+	}}
+	srv := NewBatchService(fake)
+	if err := srv.(*batchService).reload(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		pc, year  string
+		wantName  string
+		wantOrder int
+	}{
+		{name: "指定年份的规则优先", pc: "202401", year: "2024", wantName: "2024年春季", wantOrder: 10},
+		{name: "其它年份使用默认规则", pc: "202301", year: "2023", wantName: "第一批次", wantOrder: 1},
+		{name: "年份规则不匹配时回落到默认规则", pc: "202402", year: "2024", wantName: "第二批次", wantOrder: 2},
+		{name: "前后空白", pc: " 202302 ", year: "2023", wantName: "第二批次", wantOrder: 2},
+		{name: "不匹配任何规则", pc: "202399", year: "2023", wantName: model.BatchNameOther, wantOrder: math.MaxInt - 1},
+		{name: "PC 为空", pc: " ", year: "2023", wantName: model.BatchNameUnknown, wantOrder: math.MaxInt},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, order := srv.Resolve(tt.pc, tt.year)
+			if name != tt.wantName || order != tt.wantOrder {
+				t.Errorf("Resolve(%q, %q) = %q, %d, want %q, %d", tt.pc, tt.year, name, order, tt.wantName, tt.wantOrder)
+			}
+		})
+	}
+	if fake.lists != 1 {
+		t.Errorf("rules loaded %d times, want 1 (cache still fresh)", fake.lists)
+	}
+}
+
+func TestBatchResolveReloadsStaleCache(t *testing.T) {
+	fake := &fakeBatchStore{batches: []model.Batch{{ID: 1, Name: "第一批次", Pattern: "01$", SortOrder: 1}}}
+	srv := NewBatchService(fake).(*batchService)
+
+	// 从未加载过时第一次 Resolve 自动加载
+	if name, _ := srv.Resolve("202401", "2024"); name != "第一批次" {
+		t.Fatalf("Resolve = %q, want 第一批次", name)
+	}
+
+	// 其它进程修改了批次表: 有效期内仍使用缓存
+	fake.batches = []model.Batch{{ID: 1, Name: "春季批次", Pattern: "01$", SortOrder: 1}}
+	if name, _ := srv.Resolve("202401", "2024"); name != "第一批次" {
+		t.Errorf("Resolve within TTL = %q, want cached 第一批次", name)
+	}
+
+	// 超过有效期后重新加载
+	srv.loadedAt = time.Now().Add(-batchCacheTTL - time.Second)
+	if name, _ := srv.Resolve("202401", "2024"); name != "春季批次" {
+		t.Errorf("Resolve after TTL = %q, want 春季批次", name)
+	}
+
+	// 加载失败时保留旧规则，并且在下一个有效期内不再重试
+	fake.err = errors.New("connection refused")
+	srv.loadedAt = time.Now().Add(-batchCacheTTL - time.Second)
+	lists := fake.lists
+	for range 3 {
+		if name, _ := srv.Resolve("202401", "2024"); name != "春季批次" {
+			t.Errorf("Resolve after failed reload = %q, want 春季批次", name)
+		}
+	}
+	if fake.lists != lists+1 {
+		t.Errorf("reload attempts = %d, want 1", fake.lists-lists)
+	}
+}
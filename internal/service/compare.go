@@ -0,0 +1,30 @@
+package service
+
+import "ProtectedArea/internal/model"
+
+// validateCompareYear 校验对比年份，为空表示不对比
+func validateCompareYear(compareYear string) error {
+	if compareYear != "" && !yearPattern.MatchString(compareYear) {
+		return newValidationError("对比年份(compare_year)必须是四位数字: %s", compareYear)
+	}
+	return nil
+}
+
+// newComparison 计算增减量和增减率
+func newComparison(current, previous float64) model.Comparison {
+	c := model.Comparison{Current: current, Previous: previous, Change: current - previous}
+	if previous != 0 {
+		rate := (current - previous) / previous * 100
+		c.Rate = &rate
+	}
+	return c
+}
+
+// newStatComparison 个数和面积的同比
+func newStatComparison(compareYear string, count, prevCount int64, area, prevArea float64) *model.StatComparison {
+	return &model.StatComparison{
+		Year:  compareYear,
+		Count: newComparison(float64(count), float64(prevCount)),
+		Area:  newComparison(area, prevArea),
+	}
+}
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"ProtectedArea/internal/model"
+	"encoding/base64"
+	"encoding/json"
+	"slices"
+	"strings"
+)
+
+// maxCursorPageSize 游标分页每页最多返回的条数
+const maxCursorPageSize = 1000
+
+// encodeCursor 把游标编码为对前端不透明的字符串 (base64url 编码的 JSON)
+func encodeCursor(c model.Cursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor 解析 next_cursor，空串表示第一页 (返回 nil)
+func decodeCursor(s string) (*model.Cursor, error) {
+	if s == "" {
+		return nil, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, newValidationError("无效的游标(cursor)")
+	}
+	var c model.Cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.TBBH == "" {
+		return nil, newValidationError("无效的游标(cursor)")
+	}
+	return &c, nil
+}
+
+// newKeyset 根据 sort/order/cursor 生成游标分页条件
+// columns 为允许的排序字段 (值只能是 TBBH 或 BHMJ)，sort 为空时使用 defaultSort，order 为空时使用 defaultOrder
+// 返回的 Limit 比 pageSize 多 1，用于判断是否还有下一页
+func newKeyset(sort, order, cursor string, pageSize int, columns map[string]string, defaultSort, defaultOrder string) (model.Keyset, error) {
+	// 1. 校验分页大小
+	if pageSize < 1 || pageSize > maxCursorPageSize {
+		return model.Keyset{}, newValidationError("游标分页的 page_size 必须在 1-%d 之间", maxCursorPageSize)
+	}
+
+	// 2. 校验排序字段和方向
+	sort = strings.ToLower(strings.TrimSpace(sort))
+	if sort == "" {
+		sort = defaultSort
+	}
+	column, ok := columns[sort]
+	if !ok {
+		return model.Keyset{}, newValidationError("不支持的排序字段(sort): %s", sort)
+	}
+	order = strings.ToLower(strings.TrimSpace(order))
+	if order == "" {
+		order = defaultOrder
+	}
+	if order != "asc" && order != "desc" {
+		return model.Keyset{}, newValidationError("不支持的排序方向(order): %s，可选值: asc, desc", order)
+	}
+
+	// 3. 游标必须由相同的排序方式生成
+	after, err := decodeCursor(cursor)
+	if err != nil {
+		return model.Keyset{}, err
+	}
+	desc := order == "desc"
+	if after != nil && (after.Column != column || after.Desc != desc) {
+		return model.Keyset{}, newValidationError("游标(cursor)与当前的排序参数不一致")
+	}
+
+	return model.Keyset{Column: column, Desc: desc, After: after, Limit: pageSize + 1}, nil
+}
+
+// nextCursor 用本页最后一条记录生成下一页的游标
+func nextCursor(k model.Keyset, tbbh string, bhmj float64) string {
+	c := model.Cursor{Column: k.Column, Desc: k.Desc, TBBH: tbbh}
+	if k.Column != "TBBH" {
+		c.Value = bhmj
+	}
+	return encodeCursor(c)
+}
+
+// buildCursorResponse 游标分页的返回格式，total 为空表示未统计总数
+func buildCursorResponse(list interface{}, next string, total *int64, pageSize int) map[string]interface{} {
+	pagination := map[string]interface{}{
+		"next_cursor": next,       // 下一页的游标，没有下一页时为空
+		"has_more":    next != "", // 是否还有下一页
+		"page_size":   pageSize,   // 每页大小
+	}
+	if total != nil {
+		pagination["total"] = *total // 总条数 (skip_count=true 时不返回)
+	}
+
+	return map[string]interface{}{
+		"list":       list,
+		"pagination": pagination,
+	}
+}
+
+// getSpotListByCursor 图斑明细 (游标分页)
+func (s *natureService) getSpotListByCursor(req model.NatureQueryRequest) (map[string]interface{}, error) {
+	k, err := newKeyset(req.Sort, req.Order, *req.Cursor, req.PageSize, spotSortColumns, "tbbh", "asc")
+	if err != nil {
+		return nil, err
+	}
+
+	// 1. 确定查询的列: 默认与 GetSpotList 相同，另外必须包含生成游标用的 TBBH 和排序列
+	columns := []string{"TBBH", "QLX", "HLX", "BHDL"}
+	var indexes []int
+	if req.Fields != "" {
+		if columns, indexes, err = parseSpotFields(req.Fields); err != nil {
+			return nil, err
+		}
+	}
+	for _, col := range []string{"TBBH", k.Column} {
+		if !slices.Contains(columns, col) {
+			columns = append(columns, col)
+		}
+	}
+
+	// 2. 多取 1 条判断是否还有下一页
+	rows, err := s.store.GetSpotListKeyset(req, columns, k)
+	if err != nil {
+		return nil, err
+	}
+	next := ""
+	if len(rows) > req.PageSize {
+		rows = rows[:req.PageSize]
+		last := rows[len(rows)-1]
+		next = nextCursor(k, last.TBBH, last.BHMJ)
+	}
+
+	// 3. 组装列表: 指定了 fields 时只返回这些字段
+	var list interface{}
+	if indexes != nil {
+		list = projectSpots(rows, indexes)
+	} else {
+		items := make([]model.SpotListItem, len(rows))
+		for i, row := range rows {
+			items[i] = model.SpotListItem{TBBH: row.TBBH, QLX: row.QLX, HLX: row.HLX, BHDL: row.BHDL}
+		}
+		list = items
+	}
+
+	// 4. 可选统计总数
+	var total *int64
+	if !req.SkipCount {
+		count, err := s.store.CountSpots(req)
+		if err != nil {
+			return nil, err
+		}
+		total = &count
+	}
+	return buildCursorResponse(list, next, total, req.PageSize), nil
+}
+
+// getLargeSpotsByCursor 大图斑预警 (游标分页)，默认按面积从大到小
+func (s *natureService) getLargeSpotsByCursor(req model.AlertQueryRequest) (map[string]interface{}, error) {
+	k, err := newKeyset(req.Sort, req.Order, *req.Cursor, req.PageSize, largeSpotsSortColumns, "bhmj", "desc")
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := s.store.GetLargeSpotsKeyset(req, k)
+	if err != nil {
+		return nil, err
+	}
+	if list == nil {
+		list = []model.AlertSpotItem{}
+	}
+	next := ""
+	if len(list) > req.PageSize {
+		list = list[:req.PageSize]
+		last := list[len(list)-1]
+		next = nextCursor(k, last.TBBH, last.BHMJ)
+	}
+
+	var total *int64
+	if !req.SkipCount {
+		count, err := s.store.CountLargeSpots(req)
+		if err != nil {
+			return nil, err
+		}
+		total = &count
+	}
+	return buildCursorResponse(list, next, total, req.PageSize), nil
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"ProtectedArea/internal/model"
+	"ProtectedArea/pkg/errcode"
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	tests := []model.Cursor{
+		{Column: "TBBH", TBBH: "110109NR001"},
+		{Column: "BHMJ", Desc: true, Value: 12.75, TBBH: "A-1"},
+		// BHMJ 为 NULL 的图斑按 0 排序，游标值也是 0
+		{Column: "BHMJ", Value: 0, TBBH: "A_2"},
+	}
+	for _, c := range tests {
+		got, err := decodeCursor(encodeCursor(c))
+		if err != nil {
+			t.Fatalf("decodeCursor(encodeCursor(%+v)) err: %v", c, err)
+		}
+		if *got != c {
+			t.Errorf("round trip = %+v, want %+v", *got, c)
+		}
+	}
+}
+
+func TestDecodeCursor(t *testing.T) {
+	if c, err := decodeCursor(""); c != nil || err != nil {
+		t.Errorf("decodeCursor(\"\") = %v, %v, want nil, nil (first page)", c, err)
+	}
+
+	invalid := []string{
+		"not base64!",
+		base64.RawURLEncoding.EncodeToString([]byte("not json")),
+		base64.RawURLEncoding.EncodeToString([]byte(`{"c":"BHMJ","v":1}`)), // 缺少 TBBH
+	}
+	for _, s := range invalid {
+		if _, err := decodeCursor(s); !errors.Is(err, errcode.InvalidParams) {
+			t.Errorf("decodeCursor(%q) err = %v, want errcode.InvalidParams", s, err)
+		}
+	}
+}
+
+func TestNewKeyset(t *testing.T) {
+	bhmjDesc := encodeCursor(model.Cursor{Column: "BHMJ", Desc: true, Value: 3, TBBH: "A1"})
+
+	tests := []struct {
+		name     string
+		sort     string
+		order    string
+		cursor   string
+		pageSize int
+		want     model.Keyset
+		wantErr  bool
+	}{
+		{
+			name: "默认排序", pageSize: 20,
+			want: model.Keyset{Column: "TBBH", Limit: 21},
+		},
+		{
+			name: "带游标的降序", sort: "area", order: "DESC", cursor: bhmjDesc, pageSize: 10,
+			want: model.Keyset{Column: "BHMJ", Desc: true, After: &model.Cursor{Column: "BHMJ", Desc: true, Value: 3, TBBH: "A1"}, Limit: 11},
+		},
+		{name: "游标与排序列不一致", sort: "tbbh", order: "desc", cursor: bhmjDesc, pageSize: 10, wantErr: true},
+		{name: "游标与排序方向不一致", sort: "bhmj", order: "asc", cursor: bhmjDesc, pageSize: 10, wantErr: true},
+		{name: "不支持的排序字段", sort: "thsheng", pageSize: 10, wantErr: true},
+		{name: "无效的排序方向", sort: "bhmj", order: "up", pageSize: 10, wantErr: true},
+		{name: "page_size 为 0", pageSize: 0, wantErr: true},
+		{name: "page_size 超过上限", pageSize: maxCursorPageSize + 1, wantErr: true},
+		{name: "无效的游标", cursor: "%%%", pageSize: 10, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newKeyset(tt.sort, tt.order, tt.cursor, tt.pageSize, spotSortColumns, "tbbh", "asc")
+			if tt.wantErr {
+				if !errors.Is(err, errcode.InvalidParams) {
+					t.Fatalf("err = %v, want errcode.InvalidParams", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("keyset = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNextCursor(t *testing.T) {
+	// 按 TBBH 排序时不记录数值
+	c, _ := decodeCursor(nextCursor(model.Keyset{Column: "TBBH", Desc: true}, "A9", 5))
+	if want := (model.Cursor{Column: "TBBH", Desc: true, TBBH: "A9"}); *c != want {
+		t.Errorf("TBBH cursor = %+v, want %+v", *c, want)
+	}
+
+	// 生成的游标可以直接用于下一页
+	k := model.Keyset{Column: "BHMJ", Desc: true}
+	next := nextCursor(k, "A9", 5)
+	got, err := newKeyset("bhmj", "desc", next, 10, spotSortColumns, "tbbh", "asc")
+	if err != nil {
+		t.Fatalf("newKeyset with next cursor: %v", err)
+	}
+	if want := (model.Cursor{Column: "BHMJ", Desc: true, Value: 5, TBBH: "A9"}); *got.After != want {
+		t.Errorf("BHMJ cursor = %+v, want %+v", *got.After, want)
+	}
+}
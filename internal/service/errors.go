@@ -0,0 +1,8 @@
+package service
+
+import "ProtectedArea/pkg/errcode"
+
+// newValidationError 构造参数校验错误 (errcode.InvalidParams)，handler 层会返回 400
+func newValidationError(format string, args ...interface{}) error {
+	return errcode.InvalidParams.WithMessagef(format, args...)
+}
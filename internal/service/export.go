@@ -0,0 +1,153 @@
+package service
+
+import (
+	"ProtectedArea/internal/model"
+	"ProtectedArea/pkg/export"
+)
+
+// 导出接口把与 JSON 接口相同的查询结果写成表格，不分页，表头使用字段的中文含义
+
+// ExportSpotList 导出图斑明细，排序和字段选择 (sort/order/fields) 与列表接口一致，未指定 fields 时导出全部字段
+func (s *natureService) ExportSpotList(req model.NatureQueryRequest, w export.Writer) error {
+	orderBy, err := buildOrderBy(req.Sort, req.Order, spotSortColumns, "TBBH")
+	if err != nil {
+		return err
+	}
+	req.OrderBy = orderBy
+
+	var indexes []int
+	if req.Fields != "" {
+		if _, indexes, err = parseSpotFields(req.Fields); err != nil {
+			return err
+		}
+	}
+
+	header := model.NatureFieldLabels()
+	if indexes != nil {
+		header = pickValues(header, indexes)
+	}
+	if err := w.WriteRow(header...); err != nil {
+		return err
+	}
+
+	return s.store.StreamSpots(model.SpotStreamQuery{NatureQueryRequest: req}, func(spot *model.NatureData) error {
+		values := spot.Values()
+		if indexes != nil {
+			values = pickValues(values, indexes)
+		}
+		return w.WriteRow(values...)
+	})
+}
+
+// pickValues 按 NatureFields 下标取出对应的值
+func pickValues(values []interface{}, indexes []int) []interface{} {
+	picked := make([]interface{}, len(indexes))
+	for i, idx := range indexes {
+		picked[i] = values[idx]
+	}
+	return picked
+}
+
+// ExportProtectedAreaStats 导出保护地统计
+func (s *natureService) ExportProtectedAreaStats(req model.NatureQueryRequest, w export.Writer) error {
+	req.PageSize = 0 // 不分页
+	req.CompareYear = ""
+	orderBy, err := buildOrderBy(req.Sort, req.Order, protectedAreaSortColumns, "name")
+	if err != nil {
+		return err
+	}
+	req.OrderBy = orderBy
+
+	list, _, err := s.store.GetProtectedAreaStats(req)
+	if err != nil {
+		return err
+	}
+
+	if err := w.WriteRow(model.NatureFieldLabel("THBHDMC"), "图斑个数", "图斑面积"); err != nil {
+		return err
+	}
+	for _, item := range list {
+		if err := w.WriteRow(item.Name, item.Count, item.Area); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExportRegionStats 导出行政区统计，第一列表头随分组层级变化 (省/市/县)
+func (s *natureService) ExportRegionStats(year, scope, name string, userScope model.RegionScope, w export.Writer) error {
+	stats, groupCol, err := s.regionStats(year, scope, name, userScope)
+	if err != nil {
+		return err
+	}
+
+	if err := w.WriteRow(model.NatureFieldLabel(groupCol), "图斑个数", "图斑面积"); err != nil {
+		return err
+	}
+	for _, item := range stats {
+		if err := w.WriteRow(item.RegionName, item.Count, item.Area); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExportTransitionStats 导出流向分析
+func (s *natureService) ExportTransitionStats(req model.NatureQueryRequest, w export.Writer) error {
+	req.CompareYear = "" // 导出不含同比
+	stats, err := s.GetTransitionStats(req)
+	if err != nil {
+		return err
+	}
+
+	if err := w.WriteRow(model.NatureFieldLabel("HLX"), "图斑个数", "图斑面积", "个数占比(%)", "面积占比(%)"); err != nil {
+		return err
+	}
+	for _, item := range stats {
+		if err := w.WriteRow(item.HLX, item.Count, item.Area, item.CountRatio, item.AreaRatio); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExportLargeSpots 导出大面积预警图斑 (默认按面积从大到小)
+func (s *natureService) ExportLargeSpots(req model.AlertQueryRequest, w export.Writer) error {
+	orderBy, err := largeSpotsOrderBy(req)
+	if err != nil {
+		return err
+	}
+	req.OrderBy = orderBy
+
+	header := []interface{}{
+		model.NatureFieldLabel("THBHDMC"),
+		model.NatureFieldLabel("TBBH"),
+		model.NatureFieldLabel("BHMJ"),
+		model.NatureFieldLabel("THSHENG"),
+	}
+	if err := w.WriteRow(header...); err != nil {
+		return err
+	}
+
+	return s.store.StreamLargeSpots(req, func(item *model.AlertSpotItem) error {
+		return w.WriteRow(item.THBHDMC, item.TBBH, item.BHMJ, item.THSHENG)
+	})
+}
+
+// ExportDamageBatchStats 导出资源损毁分批次统计
+func (s *natureService) ExportDamageBatchStats(year string, userScope model.RegionScope, w export.Writer) error {
+	stats, err := s.damageStatsByBatch(year, userScope)
+	if err != nil {
+		return err
+	}
+
+	if err := w.WriteRow(model.NatureFieldLabel("PC"), "资源损毁个数", "资源损毁面积"); err != nil {
+		return err
+	}
+	for _, item := range stats {
+		if err := w.WriteRow(item.PC, item.Count, item.Area); err != nil {
+			return err
+		}
+	}
+	return nil
+}
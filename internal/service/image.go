@@ -0,0 +1,493 @@
+package service
+
+import (
+	"ProtectedArea/internal/model"
+	"ProtectedArea/internal/store"
+	"ProtectedArea/pkg/errcode"
+	"ProtectedArea/pkg/thumbnail"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg" // 注册 jpeg 解码器，用于读取图片尺寸
+	_ "image/png"  // 注册 png 解码器
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ImageService interface {
+	// ListSpotImages 图斑的全部图片及前后对比配对，图斑不存在或超出用户数据范围时返回 errcode.SpotNotFound
+	ListSpotImages(tbbh string, userScope model.RegionScope) (*model.SpotImages, error)
+	// GetImageFile 返回图片文件路径，图片不存在或所属图斑超出用户数据范围时返回 errcode.ImageNotFound
+	GetImageFile(id uint, userScope model.RegionScope) (string, error)
+	// FindSpotImage 旧接口 /api/image 使用: 优先返回索引中的变化后影像，索引中没有时按 TBBH.jpg 查找
+	// 图斑超出用户数据范围时返回 errcode.ImageNotFound
+	FindSpotImage(tbbh string, userScope model.RegionScope) (string, error)
+	// PackImages 返回这些图斑的全部图片文件 (索引中的图片，没有索引时按 TBBH.jpg 查找)，用于打包下载
+	// 调用方负责校验图斑在用户的数据范围内
+	PackImages(tbbhs []string) ([]model.PackImage, error)
+	// PrepareImage 准备要输出的图片: 未指定缩放参数时为原图，否则为缩略图缓存文件 (不存在时生成)
+	PrepareImage(path string, req model.ImageResizeRequest) (*model.ImageFile, error)
+	// Rescan 扫描图片目录并同步索引表 (同一时间只允许一个扫描)
+	Rescan() (*model.ImageScanResult, error)
+	// CheckImages 对照 nature_data 检查图片目录: 缺图、孤立文件、无法读取的图片和重复文件
+	CheckImages(req model.ImageCheckRequest) (*model.ImageCheckReport, error)
+
+	// Upload 上传图斑图片 (只支持 JPEG/PNG)，同一角色和序号已有图片时返回 errcode.ImageExists
+	Upload(tbbh string, input model.ImageUploadInput, file io.Reader, op model.Operator) (*model.SpotImage, error)
+	// Replace 替换图片文件，角色和序号不变
+	Replace(id uint, file io.Reader, op model.Operator) (*model.SpotImage, error)
+	// Delete 删除图片文件及索引
+	Delete(id uint, op model.Operator) error
+	// ListAudits 图片变更记录
+	ListAudits(req model.ImageAuditQueryRequest) (map[string]interface{}, error)
+}
+
+type imageService struct {
+	store       store.ImageStore
+	natureStore store.NatureStore // 用于校验数据范围和读取拍摄日期 (SQSJ/BQSJ)
+	root        string            // 图片存放的根目录 (来自配置 image.root)
+	thumbnails  *thumbnail.Cache  // 缩略图缓存
+
+	// mu 扫描和上传/替换/删除互斥，避免扫描时把正在写入的文件当作新文件
+	mu sync.Mutex
+}
+
+// NewImageService cacheDir 为缩略图缓存目录
+func NewImageService(s store.ImageStore, natureStore store.NatureStore, root, cacheDir string) ImageService {
+	return &imageService{store: s, natureStore: natureStore, root: root, thumbnails: thumbnail.NewCache(cacheDir)}
+}
+
+// imageExtensions 支持的图片后缀 -> 格式
+var imageExtensions = map[string]string{
+	".jpg":  "jpeg",
+	".jpeg": "jpeg",
+	".png":  "png",
+}
+
+// imageNamePattern 带角色的文件名: TBBH_before / TBBH_after_2 / TBBH_field_1
+// 不匹配的文件名整体作为 TBBH，角色取 after: 改造前每个图斑只有一张 TBBH.jpg，展示的是变化后影像。
+// 因此 TBBH 本身不能以角色后缀结尾 (见 hasImageRoleSuffix)，否则 A_after.jpg 会被识别为图斑 A 的变化后影像
+var imageNamePattern = regexp.MustCompile(`^(.+)_(before|after|field)(?:_(\d+))?$`)
+
+// imageRoleOrder 图片列表中各角色的顺序
+var imageRoleOrder = map[string]int{
+	model.ImageRoleBefore: 0,
+	model.ImageRoleAfter:  1,
+	model.ImageRoleField:  2,
+}
+
+// parseImageName 根据文件名识别图斑编号、角色和序号，不是图片或无法识别时 ok 为 false
+// 不带角色后缀的旧文件 (TBBH.jpg) 视为变化后影像
+func parseImageName(name string) (tbbh, role string, seq int, ok bool) {
+	ext := strings.ToLower(filepath.Ext(name))
+	if _, ok := imageExtensions[ext]; !ok {
+		return "", "", 0, false
+	}
+	base := strings.TrimSuffix(name, filepath.Ext(name))
+
+	m := imageNamePattern.FindStringSubmatch(base)
+	if m == nil {
+		return base, model.ImageRoleAfter, 1, base != ""
+	}
+	seq = 1
+	if m[3] != "" {
+		n, err := strconv.Atoi(m[3])
+		if err != nil || n < 1 {
+			return "", "", 0, false
+		}
+		seq = n
+	}
+	return m[1], m[2], seq, true
+}
+
+func (s *imageService) ListSpotImages(tbbh string, userScope model.RegionScope) (*model.SpotImages, error) {
+	// 1. 图斑必须存在且在用户的数据范围内
+	if _, err := s.natureStore.GetSpot(tbbh, userScope); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errcode.SpotNotFound
+		}
+		return nil, err
+	}
+
+	// 2. 按 变化前、变化后、外业照片 排序 (同一角色内 Store 已按序号排序)
+	images, err := s.store.ListByTBBH(tbbh)
+	if err != nil {
+		return nil, err
+	}
+	if images == nil {
+		images = []model.SpotImage{}
+	}
+	sort.SliceStable(images, func(i, j int) bool {
+		return imageRoleOrder[images[i].Role] < imageRoleOrder[images[j].Role]
+	})
+
+	// 3. 每个角色的第一张作为前后对比的一对
+	result := &model.SpotImages{TBBH: tbbh, Images: images}
+	for i := range images {
+		switch {
+		case images[i].Role == model.ImageRoleBefore && result.Before == nil:
+			result.Before = &images[i]
+		case images[i].Role == model.ImageRoleAfter && result.After == nil:
+			result.After = &images[i]
+		}
+	}
+	return result, nil
+}
+
+func (s *imageService) GetImageFile(id uint, userScope model.RegionScope) (string, error) {
+	img, err := s.store.GetByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", errcode.ImageNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+
+	// 受限用户只能查看范围内图斑的图片
+	if !userScope.IsZero() {
+		if _, err := s.natureStore.GetSpot(img.TBBH, userScope); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return "", errcode.ImageNotFound
+			}
+			return "", err
+		}
+	}
+
+	// 索引可能落后于磁盘 (文件已被删除但尚未重新扫描)
+	path := filepath.Join(s.root, filepath.FromSlash(img.Path))
+	if _, err := os.Stat(path); err != nil {
+		return "", errcode.ImageNotFound
+	}
+	return path, nil
+}
+
+func (s *imageService) FindSpotImage(tbbh string, userScope model.RegionScope) (string, error) {
+	// TBBH 会拼接到文件路径中，必须先校验
+	if !tbbhPattern.MatchString(tbbh) {
+		return "", newValidationError("图斑编号格式不正确: %s", tbbh)
+	}
+
+	// 受限用户只能查看范围内图斑的图片 (与 GetImageFile 一致)
+	if !userScope.IsZero() {
+		if _, err := s.natureStore.GetSpot(tbbh, userScope); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return "", errcode.ImageNotFound
+			}
+			return "", err
+		}
+	}
+	images, err := s.store.ListByTBBH(tbbh)
+	if err != nil {
+		return "", err
+	}
+	// 索引按序号排序，取第一张变化后影像，没有时取第一张任意图片
+	var picked *model.SpotImage
+	for i := range images {
+		if images[i].Role == model.ImageRoleAfter {
+			picked = &images[i]
+			break
+		}
+	}
+	if picked == nil && len(images) > 0 {
+		picked = &images[0]
+	}
+	if picked != nil {
+		path := filepath.Join(s.root, filepath.FromSlash(picked.Path))
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+
+	// 尚未扫描的新文件
+	if path, ok := findLegacyImage(s.root, tbbh); ok {
+		return path, nil
+	}
+	return "", errcode.ImageNotFound
+}
+
+func (s *imageService) PackImages(tbbhs []string) ([]model.PackImage, error) {
+	images, err := s.store.ListByTBBHs(tbbhs)
+	if err != nil {
+		return nil, err
+	}
+	byTBBH := make(map[string][]model.SpotImage, len(tbbhs))
+	for _, img := range images {
+		byTBBH[img.TBBH] = append(byTBBH[img.TBBH], img)
+	}
+
+	// 按传入的图斑顺序输出，跳过索引中已不存在的文件
+	var result []model.PackImage
+	for _, tbbh := range tbbhs {
+		for _, img := range byTBBH[tbbh] {
+			path := filepath.Join(s.root, filepath.FromSlash(img.Path))
+			if info, err := os.Stat(path); err == nil {
+				result = append(result, model.PackImage{TBBH: tbbh, Name: "images/" + img.Path, Path: path, ModTime: info.ModTime()})
+			}
+		}
+		if len(byTBBH[tbbh]) > 0 || !tbbhPattern.MatchString(tbbh) {
+			continue
+		}
+		if path, ok := findLegacyImage(s.root, tbbh); ok {
+			if info, err := os.Stat(path); err == nil {
+				result = append(result, model.PackImage{TBBH: tbbh, Name: "images/" + filepath.Base(path), Path: path, ModTime: info.ModTime()})
+			}
+		}
+	}
+	return result, nil
+}
+
+func (s *imageService) PrepareImage(path string, req model.ImageResizeRequest) (*model.ImageFile, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, errcode.ImageNotFound
+	}
+
+	// 1. 原图
+	if req.IsZero() {
+		return &model.ImageFile{
+			Path:    path,
+			ModTime: info.ModTime(),
+			ETag:    `"` + thumbnail.Key(path, info.ModTime(), info.Size(), thumbnail.Options{}) + `"`,
+		}, nil
+	}
+
+	// 2. 校验缩放参数
+	opts, err := resizeOptions(path, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. 取缓存，不存在时生成
+	thumb, err := s.thumbnails.Get(path, info, opts)
+	if errors.Is(err, thumbnail.ErrDecode) {
+		return nil, errcode.BusinessRule.WithMessage("图片文件已损坏或格式不受支持，无法生成缩略图")
+	}
+	if errors.Is(err, thumbnail.ErrTooLarge) {
+		return nil, errcode.BusinessRule.WithMessagef("图片像素超过 %d 万，无法生成缩略图，请下载原图", thumbnail.MaxPixels/10000)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("生成缩略图失败: %w", err)
+	}
+	return &model.ImageFile{
+		Path:    thumb,
+		ModTime: info.ModTime(),
+		ETag:    `"` + thumbnail.Key(path, info.ModTime(), info.Size(), opts) + `"`,
+	}, nil
+}
+
+// resizeOptions 校验缩放参数，未指定格式时与原图相同
+func resizeOptions(path string, req model.ImageResizeRequest) (thumbnail.Options, error) {
+	if req.Width < 0 || req.Width > thumbnail.MaxSize || req.Height < 0 || req.Height > thumbnail.MaxSize {
+		return thumbnail.Options{}, newValidationError("width/height 必须在 0-%d 之间", thumbnail.MaxSize)
+	}
+	if req.Quality < 0 || req.Quality > 100 {
+		return thumbnail.Options{}, newValidationError("quality 必须在 1-100 之间")
+	}
+
+	format := strings.ToLower(strings.TrimSpace(req.Format))
+	switch format {
+	case "":
+		format = imageExtensions[strings.ToLower(filepath.Ext(path))]
+		if format == "" {
+			format = thumbnail.FormatJPEG
+		}
+	case "jpg":
+		format = thumbnail.FormatJPEG
+	}
+	if !thumbnail.IsSupported(format) {
+		return thumbnail.Options{}, newValidationError("不支持的图片格式(format): %s，可选值: jpeg, png, webp", req.Format)
+	}
+
+	// quality 只影响 JPEG，其它格式忽略，避免同一张缩略图缓存多份
+	quality := req.Quality
+	if format != thumbnail.FormatJPEG {
+		quality = 0
+	} else if quality == 0 {
+		quality = thumbnail.DefaultQuality
+	}
+	// 宽高和质量取整到标准值，限制缓存文件的数量
+	return thumbnail.Snap(thumbnail.Options{Width: req.Width, Height: req.Height, Quality: quality, Format: format}), nil
+}
+
+// Rescan 扫描图片目录并与索引表对比:
+// 新文件新增索引；大小或修改时间变化的文件重新读取尺寸；已不存在的文件删除索引
+// 以 . 开头的目录 (例如缩略图缓存) 不扫描
+func (s *imageService) Rescan() (*model.ImageScanResult, error) {
+	if !s.mu.TryLock() {
+		return nil, errcode.Conflict.WithMessage("图片目录正在扫描或写入中，请稍后再试")
+	}
+	defer s.mu.Unlock()
+	start := time.Now()
+
+	// 1. 读取现有索引
+	existing, err := s.store.List()
+	if err != nil {
+		return nil, err
+	}
+	byPath := make(map[string]model.SpotImage, len(existing))
+	for _, img := range existing {
+		byPath[img.Path] = img
+	}
+
+	// 2. 遍历图片目录
+	result := &model.ImageScanResult{}
+	var found []model.SpotImage
+	seen := make(map[string]bool)
+	err = filepath.WalkDir(s.root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != s.root && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		tbbh, role, seq, ok := parseImageName(d.Name())
+		if !ok {
+			result.Skipped++
+			return nil
+		}
+		rel, err := filepath.Rel(s.root, path)
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		img := model.SpotImage{
+			TBBH:    tbbh,
+			Role:    role,
+			Seq:     seq,
+			Path:    filepath.ToSlash(rel),
+			Size:    info.Size(),
+			ModTime: info.ModTime().Truncate(time.Second), // 数据库只保存到秒
+		}
+		// 文件没有变化时沿用已有的尺寸信息，避免每次都解码全部图片
+		if old, ok := byPath[img.Path]; ok && old.Size == img.Size && old.ModTime.Unix() == img.ModTime.Unix() {
+			img.Format, img.Width, img.Height = old.Format, old.Width, old.Height
+		} else if err := readImageConfig(path, &img); err != nil {
+			log.Printf("跳过无法解析的图片 %s: %v", path, err)
+			result.Skipped++
+			return nil
+		}
+
+		result.Scanned++
+		seen[img.Path] = true
+		found = append(found, img)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("扫描图片目录 %s 失败: %w", s.root, err)
+	}
+
+	// 3. 填充拍摄日期
+	if err := s.fillCaptureDates(found); err != nil {
+		return nil, err
+	}
+
+	// 4. 与现有索引对比
+	var creates, updates []model.SpotImage
+	for _, img := range found {
+		old, ok := byPath[img.Path]
+		if !ok {
+			creates = append(creates, img)
+			continue
+		}
+		if imageChanged(old, img) {
+			img.ID, img.CreatedAt = old.ID, old.CreatedAt
+			updates = append(updates, img)
+		}
+	}
+	var deletes []uint
+	for _, img := range existing {
+		if !seen[img.Path] {
+			deletes = append(deletes, img.ID)
+		}
+	}
+
+	if err := s.store.Sync(creates, updates, deletes); err != nil {
+		return nil, err
+	}
+	result.Added, result.Updated, result.Removed = len(creates), len(updates), len(deletes)
+	result.Duration = time.Since(start).Round(time.Millisecond).String()
+	return result, nil
+}
+
+// readImageConfig 只解码图片头部，读取格式和尺寸
+func readImageConfig(path string, img *model.SpotImage) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	cfg, format, err := image.DecodeConfig(f)
+	if err != nil {
+		return err
+	}
+	img.Format, img.Width, img.Height = format, cfg.Width, cfg.Height
+	return nil
+}
+
+// fillCaptureDates 批量填充拍摄日期
+func (s *imageService) fillCaptureDates(images []model.SpotImage) error {
+	var tbbhs []string
+	added := make(map[string]bool)
+	for _, img := range images {
+		if img.Role != model.ImageRoleField && !added[img.TBBH] {
+			added[img.TBBH] = true
+			tbbhs = append(tbbhs, img.TBBH)
+		}
+	}
+
+	spots, err := s.natureStore.GetSpotsByTBBH(tbbhs, model.RegionScope{})
+	if err != nil {
+		return err
+	}
+	byTBBH := make(map[string]*model.NatureData, len(spots))
+	for i := range spots {
+		byTBBH[spots[i].TBBH] = &spots[i]
+	}
+
+	for i := range images {
+		images[i].CaptureDate = captureDate(&images[i], byTBBH[images[i].TBBH])
+	}
+	return nil
+}
+
+// captureDate 拍摄日期: 变化前/后影像取图斑的 SQSJ/BQSJ (图斑不存在时为空)，外业照片取文件修改日期
+func captureDate(img *model.SpotImage, spot *model.NatureData) string {
+	switch {
+	case img.Role == model.ImageRoleField:
+		return img.ModTime.Format("2006-01-02")
+	case spot == nil:
+		return ""
+	case img.Role == model.ImageRoleBefore:
+		return spot.SQSJ
+	default:
+		return spot.BQSJ
+	}
+}
+
+// imageChanged 判断扫描结果与已有索引是否不同 (需要更新)
+func imageChanged(old, cur model.SpotImage) bool {
+	return old.TBBH != cur.TBBH || old.Role != cur.Role || old.Seq != cur.Seq ||
+		old.Size != cur.Size || old.ModTime.Unix() != cur.ModTime.Unix() ||
+		old.Format != cur.Format || old.Width != cur.Width || old.Height != cur.Height ||
+		old.CaptureDate != cur.CaptureDate
+}
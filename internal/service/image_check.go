@@ -0,0 +1,262 @@
+package service
+
+import (
+	"ProtectedArea/internal/model"
+	"ProtectedArea/pkg/export"
+	"errors"
+	"fmt"
+	"image"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// imageIssueOrder 问题明细中各类型的顺序
+var imageIssueOrder = map[string]int{
+	model.ImageIssueMissing:   0,
+	model.ImageIssueOrphan:    1,
+	model.ImageIssueCorrupt:   2,
+	model.ImageIssueDuplicate: 3,
+}
+
+// imageIssueLabels 导出表格时问题类型的中文名
+var imageIssueLabels = map[string]string{
+	model.ImageIssueMissing:   "缺图",
+	model.ImageIssueOrphan:    "孤立文件",
+	model.ImageIssueCorrupt:   "图片损坏",
+	model.ImageIssueDuplicate: "重复文件",
+}
+
+// imageFileKey 同一图斑、角色和序号只应有一个文件
+type imageFileKey struct {
+	tbbh string
+	role string
+	seq  int
+}
+
+// CheckImages 对照 nature_data 检查图片目录:
+// 筛选范围内没有可用图片的图斑、图斑不存在的孤立文件、无法读取的图片，以及同一图斑/角色/序号的重复文件
+// 只读取文件和索引，不修改任何内容
+func (s *imageService) CheckImages(req model.ImageCheckRequest) (*model.ImageCheckReport, error) {
+	view := strings.ToLower(strings.TrimSpace(req.View))
+	if view != "" && view != model.ImageCheckViewIssues && view != model.ImageCheckViewMissing {
+		return nil, newValidationError("不支持的视图(view): %s，可选值: issues, missing", req.View)
+	}
+	start := time.Now()
+
+	// 1. 全部图斑 (孤立文件对照全部图斑，缺图只统计筛选范围内的图斑)
+	spots, err := s.natureStore.ListSpotKeys()
+	if err != nil {
+		return nil, err
+	}
+	byTBBH := make(map[string]*model.NatureData, len(spots))
+	for i := range spots {
+		byTBBH[spots[i].TBBH] = &spots[i]
+	}
+
+	// 2. 现有索引: 快速检查时，文件与索引一致说明扫描时已经成功读取过
+	indexed, err := s.store.List()
+	if err != nil {
+		return nil, err
+	}
+	byPath := make(map[string]*model.SpotImage, len(indexed))
+	for i := range indexed {
+		byPath[indexed[i].Path] = &indexed[i]
+	}
+
+	// 3. 遍历图片目录 (与 Rescan 一样跳过以 . 开头的目录)
+	report := &model.ImageCheckReport{}
+	files := make(map[imageFileKey][]string)
+	hasFile := make(map[string]bool)  // 有图片文件的图斑
+	readable := make(map[string]bool) // 有可用图片的图斑
+	err = filepath.WalkDir(s.root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != s.root && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		tbbh, role, seq, ok := parseImageName(d.Name())
+		if !ok {
+			return nil
+		}
+		rel, err := filepath.Rel(s.root, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		report.Summary.Files++
+
+		// 孤立文件只报告一次，不再检查能否读取或是否重复
+		spot := byTBBH[tbbh]
+		if spot == nil {
+			report.Issues = append(report.Issues, newImageIssue(model.ImageIssueOrphan, tbbh, rel, nil, "图斑不存在"))
+			return nil
+		}
+
+		key := imageFileKey{tbbh: tbbh, role: role, seq: seq}
+		files[key] = append(files[key], rel)
+		hasFile[tbbh] = true
+
+		if err := checkImageFile(path, d, byPath[rel], req.Deep); err != nil {
+			report.Issues = append(report.Issues, newImageIssue(model.ImageIssueCorrupt, tbbh, rel, spot, err.Error()))
+			return nil
+		}
+		readable[tbbh] = true
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("扫描图片目录 %s 失败: %w", s.root, err)
+	}
+
+	// 4. 重复文件，例如 TBBH.jpg 和 TBBH.png、TBBH.jpg 和 TBBH_after.jpg
+	for key, paths := range files {
+		if len(paths) < 2 {
+			continue
+		}
+		sort.Strings(paths)
+		report.Issues = append(report.Issues,
+			newImageIssue(model.ImageIssueDuplicate, key.tbbh, paths[0], byTBBH[key.tbbh], strings.Join(paths, ", ")))
+	}
+
+	// 5. 筛选范围内的缺图图斑，按 年份/批次/省 汇总
+	groups := make(map[model.ImageMissingGroup]*model.ImageMissingGroup)
+	for i := range spots {
+		spot := &spots[i]
+		if (req.Year != "" && spot.Year != req.Year) || (req.PC != "" && spot.PC != req.PC) {
+			continue
+		}
+		groupKey := model.ImageMissingGroup{Year: spot.Year, PC: spot.PC, Province: spot.THSHENG}
+		group := groups[groupKey]
+		if group == nil {
+			group = &groupKey
+			groups[groupKey] = group
+		}
+		group.Spots++
+		report.Summary.Spots++
+
+		if readable[spot.TBBH] {
+			report.Summary.WithImages++
+			continue
+		}
+		group.Missing++
+		detail := ""
+		if hasFile[spot.TBBH] {
+			detail = "图片均无法读取"
+		}
+		report.Issues = append(report.Issues, newImageIssue(model.ImageIssueMissing, spot.TBBH, "", spot, detail))
+	}
+
+	// 6. 排序并汇总
+	report.Missing = make([]model.ImageMissingGroup, 0, len(groups))
+	for _, group := range groups {
+		report.Missing = append(report.Missing, *group)
+	}
+	sort.Slice(report.Missing, func(i, j int) bool {
+		a, b := report.Missing[i], report.Missing[j]
+		if a.Year != b.Year {
+			return a.Year < b.Year
+		}
+		if a.PC != b.PC {
+			return a.PC < b.PC
+		}
+		return a.Province < b.Province
+	})
+
+	if report.Issues == nil {
+		report.Issues = []model.ImageIssue{}
+	}
+	sort.Slice(report.Issues, func(i, j int) bool {
+		a, b := report.Issues[i], report.Issues[j]
+		if a.Type != b.Type {
+			return imageIssueOrder[a.Type] < imageIssueOrder[b.Type]
+		}
+		if a.TBBH != b.TBBH {
+			return a.TBBH < b.TBBH
+		}
+		return a.Path < b.Path
+	})
+	for _, issue := range report.Issues {
+		switch issue.Type {
+		case model.ImageIssueMissing:
+			report.Summary.Missing++
+		case model.ImageIssueOrphan:
+			report.Summary.Orphan++
+		case model.ImageIssueCorrupt:
+			report.Summary.Corrupt++
+		case model.ImageIssueDuplicate:
+			report.Summary.Duplicate++
+		}
+	}
+	report.Summary.Duration = time.Since(start).Round(time.Millisecond).String()
+	return report, nil
+}
+
+// newImageIssue spot 为空时 (孤立文件) 不填年份、批次和省
+func newImageIssue(typ, tbbh, path string, spot *model.NatureData, detail string) model.ImageIssue {
+	issue := model.ImageIssue{Type: typ, TBBH: tbbh, Path: path, Detail: detail}
+	if spot != nil {
+		issue.Year, issue.PC, issue.Province = spot.Year, spot.PC, spot.THSHENG
+	}
+	return issue
+}
+
+// checkImageFile 检查图片能否读取: deep 为 true 时完整解码，否则只读取文件头
+// 非 deep 模式下文件的大小和修改时间与索引一致时直接视为可读
+func checkImageFile(path string, d fs.DirEntry, indexed *model.SpotImage, deep bool) error {
+	if !deep && indexed != nil {
+		if info, err := d.Info(); err == nil && info.Size() == indexed.Size && info.ModTime().Unix() == indexed.ModTime.Unix() {
+			return nil
+		}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		// 只保留错误原因，不在报告中暴露服务器上的绝对路径
+		var pathErr *fs.PathError
+		if errors.As(err, &pathErr) {
+			return pathErr.Err
+		}
+		return err
+	}
+	defer f.Close()
+
+	if deep {
+		_, _, err = image.Decode(f)
+	} else {
+		_, _, err = image.DecodeConfig(f)
+	}
+	return err
+}
+
+// WriteImageCheckReport 把检查报告写成表格: view 为 missing 时输出缺图统计，否则输出问题明细
+func WriteImageCheckReport(report *model.ImageCheckReport, view string, w export.Writer) error {
+	if strings.EqualFold(strings.TrimSpace(view), model.ImageCheckViewMissing) {
+		if err := w.WriteRow(model.NatureFieldLabel("year"), model.NatureFieldLabel("PC"), model.NatureFieldLabel("THSHENG"), "图斑个数", "缺图个数"); err != nil {
+			return err
+		}
+		for _, group := range report.Missing {
+			if err := w.WriteRow(group.Year, group.PC, group.Province, group.Spots, group.Missing); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := w.WriteRow("问题类型", model.NatureFieldLabel("TBBH"), "文件", model.NatureFieldLabel("year"), model.NatureFieldLabel("PC"), model.NatureFieldLabel("THSHENG"), "说明"); err != nil {
+		return err
+	}
+	for _, issue := range report.Issues {
+		if err := w.WriteRow(imageIssueLabels[issue.Type], issue.TBBH, issue.Path, issue.Year, issue.PC, issue.Province, issue.Detail); err != nil {
+			return err
+		}
+	}
+	return nil
+}
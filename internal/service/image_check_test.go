@@ -0,0 +1,133 @@
+package service
+
+import (
+	"ProtectedArea/internal/model"
+	"ProtectedArea/internal/store"
+	"ProtectedArea/pkg/errcode"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// fakeCheckImageStore 图片索引为空，快速检查时所有文件都需要读取文件头
+type fakeCheckImageStore struct {
+	store.ImageStore
+}
+
+func (fakeCheckImageStore) List() ([]model.SpotImage, error) {
+	return nil, nil
+}
+
+// fakeCheckSpotStore 返回固定的图斑
+type fakeCheckSpotStore struct {
+	store.NatureStore
+	spots []model.NatureData
+}
+
+func (f fakeCheckSpotStore) ListSpotKeys() ([]model.NatureData, error) {
+	return append([]model.NatureData(nil), f.spots...), nil
+}
+
+func TestCheckImages(t *testing.T) {
+	root := t.TempDir()
+	jpg, png := encodeJPEG(t, 4, 4), encodePNG(t, 4, 4)
+	for name, data := range map[string][]byte{
+		"A1_after.jpg":           jpg,              // 正常
+		"sub/A2.jpg":             jpg,              // 与 A2_after.png 重复
+		"A2_after.png":           png,              //
+		"A3_before.jpg":          []byte("broken"), // 损坏，A3 因此缺图
+		"Z9_after.jpg":           []byte("broken"), // 孤立文件，不再报告损坏
+		"Z9_field_1.jpg":         jpg,              // 孤立文件
+		"readme.txt":             []byte("ignored"),
+		".trash/A4_after.jpg":    jpg, // 以 . 开头的目录被跳过
+		".upload-123.tmp":        []byte("ignored"),
+		"2023/A5_field_2.jpeg":   jpg,
+		"2023/A5_field_0.jpg":    jpg, // 序号无效，不是图片文件名
+		"2023/A5_field_x.jpg.db": []byte("ignored"),
+	} {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	spots := fakeCheckSpotStore{spots: []model.NatureData{
+		{TBBH: "A1", Year: "2024", PC: "202401", THSHENG: "河北省"},
+		{TBBH: "A2", Year: "2024", PC: "202401", THSHENG: "河北省"},
+		{TBBH: "A3", Year: "2024", PC: "202402", THSHENG: "山西省"},
+		{TBBH: "A4", Year: "2024", PC: "202402", THSHENG: "山西省"},
+		{TBBH: "A5", Year: "2023", PC: "202301", THSHENG: "河北省"},
+	}}
+	srv := NewImageService(fakeCheckImageStore{}, spots, root, t.TempDir())
+
+	report, err := srv.CheckImages(model.ImageCheckRequest{Year: "2024"})
+	if err != nil {
+		t.Fatalf("CheckImages: %v", err)
+	}
+
+	wantIssues := []model.ImageIssue{
+		{Type: model.ImageIssueMissing, TBBH: "A3", Year: "2024", PC: "202402", Province: "山西省", Detail: "图片均无法读取"},
+		{Type: model.ImageIssueMissing, TBBH: "A4", Year: "2024", PC: "202402", Province: "山西省"},
+		{Type: model.ImageIssueOrphan, TBBH: "Z9", Path: "Z9_after.jpg", Detail: "图斑不存在"},
+		{Type: model.ImageIssueOrphan, TBBH: "Z9", Path: "Z9_field_1.jpg", Detail: "图斑不存在"},
+		{Type: model.ImageIssueCorrupt, TBBH: "A3", Path: "A3_before.jpg", Year: "2024", PC: "202402", Province: "山西省"},
+		{Type: model.ImageIssueDuplicate, TBBH: "A2", Path: "A2_after.png", Year: "2024", PC: "202401", Province: "河北省", Detail: "A2_after.png, sub/A2.jpg"},
+	}
+	if len(report.Issues) != len(wantIssues) {
+		t.Fatalf("issues = %+v\nwant %+v", report.Issues, wantIssues)
+	}
+	for i, want := range wantIssues {
+		got := report.Issues[i]
+		if want.Type == model.ImageIssueCorrupt {
+			// 损坏的原因来自解码器，只检查有说明
+			if got.Detail == "" {
+				t.Errorf("corrupt issue without detail: %+v", got)
+			}
+			got.Detail = ""
+		}
+		if got != want {
+			t.Errorf("issues[%d] = %+v\nwant %+v", i, got, want)
+		}
+	}
+
+	wantSummary := model.ImageCheckSummary{Spots: 4, WithImages: 2, Files: 7, Missing: 2, Orphan: 2, Corrupt: 1, Duplicate: 1}
+	report.Summary.Duration = ""
+	if report.Summary != wantSummary {
+		t.Errorf("summary = %+v\nwant %+v", report.Summary, wantSummary)
+	}
+	wantMissing := []model.ImageMissingGroup{
+		{Year: "2024", PC: "202401", Province: "河北省", Spots: 2, Missing: 0},
+		{Year: "2024", PC: "202402", Province: "山西省", Spots: 2, Missing: 2},
+	}
+	if !reflect.DeepEqual(report.Missing, wantMissing) {
+		t.Errorf("missing = %+v\nwant %+v", report.Missing, wantMissing)
+	}
+
+	// 导出两种视图
+	w := &rowsWriter{}
+	if err := WriteImageCheckReport(report, "missing", w); err != nil {
+		t.Fatal(err)
+	}
+	if len(w.rows) != 1+len(wantMissing) {
+		t.Errorf("missing view rows = %d, want %d", len(w.rows), 1+len(wantMissing))
+	}
+	w = &rowsWriter{}
+	if err := WriteImageCheckReport(report, "", w); err != nil {
+		t.Fatal(err)
+	}
+	if len(w.rows) != 1+len(wantIssues) || w.rows[3][0] != "孤立文件" {
+		t.Errorf("issues view rows = %v", w.rows)
+	}
+}
+
+func TestCheckImagesInvalidView(t *testing.T) {
+	srv := NewImageService(fakeCheckImageStore{}, fakeCheckSpotStore{}, t.TempDir(), t.TempDir())
+	if _, err := srv.CheckImages(model.ImageCheckRequest{View: "all"}); !errors.Is(err, errcode.InvalidParams) {
+		t.Errorf("err = %v, want errcode.InvalidParams", err)
+	}
+}
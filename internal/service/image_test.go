@@ -0,0 +1,89 @@
+package service
+
+import (
+	"ProtectedArea/internal/model"
+	"ProtectedArea/pkg/errcode"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseImageName(t *testing.T) {
+	tests := []struct {
+		name     string
+		wantTBBH string
+		wantRole string
+		wantSeq  int
+		wantOK   bool
+	}{
+		{name: "A1_before.jpg", wantTBBH: "A1", wantRole: model.ImageRoleBefore, wantSeq: 1, wantOK: true},
+		{name: "A1_after_2.PNG", wantTBBH: "A1", wantRole: model.ImageRoleAfter, wantSeq: 2, wantOK: true},
+		{name: "A1_field_12.jpeg", wantTBBH: "A1", wantRole: model.ImageRoleField, wantSeq: 12, wantOK: true},
+		{name: "110109_NR_001_field_3.jpg", wantTBBH: "110109_NR_001", wantRole: model.ImageRoleField, wantSeq: 3, wantOK: true},
+		// 不带角色后缀的旧文件视为变化后影像
+		{name: "A1.jpg", wantTBBH: "A1", wantRole: model.ImageRoleAfter, wantSeq: 1, wantOK: true},
+		{name: "A1_front.jpg", wantTBBH: "A1_front", wantRole: model.ImageRoleAfter, wantSeq: 1, wantOK: true},
+		{name: "A1_After.jpg", wantTBBH: "A1_After", wantRole: model.ImageRoleAfter, wantSeq: 1, wantOK: true},
+		{name: "A1_field_0.jpg", wantOK: false},
+		{name: "A1_field_99999999999999999999.jpg", wantOK: false},
+		{name: "A1_before.gif", wantOK: false},
+		{name: "A1", wantOK: false},
+		{name: ".jpg", wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tbbh, role, seq, ok := parseImageName(tt.name)
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if tbbh != tt.wantTBBH || role != tt.wantRole || seq != tt.wantSeq {
+				t.Errorf("parseImageName = %q, %q, %d, want %q, %q, %d", tbbh, role, seq, tt.wantTBBH, tt.wantRole, tt.wantSeq)
+			}
+		})
+	}
+}
+
+func TestHasImageRoleSuffix(t *testing.T) {
+	tests := []struct {
+		tbbh string
+		want bool
+	}{
+		{tbbh: "A1", want: false},
+		{tbbh: "110109_NR_001", want: false},
+		{tbbh: "A1_afterwards", want: false},
+		{tbbh: "A1_field_", want: false},
+		{tbbh: "A1_before", want: true},
+		{tbbh: "A1_after", want: true},
+		{tbbh: "A1_field_2", want: true},
+		{tbbh: "A1_after_0", want: true},
+	}
+	for _, tt := range tests {
+		if got := hasImageRoleSuffix(tt.tbbh); got != tt.want {
+			t.Errorf("hasImageRoleSuffix(%q) = %v, want %v", tt.tbbh, got, tt.want)
+		}
+	}
+
+	// 允许的编号写成旧文件名后能原样识别回来
+	for _, tt := range tests {
+		if tt.want {
+			continue
+		}
+		if tbbh, role, _, ok := parseImageName(tt.tbbh + ".jpg"); !ok || tbbh != tt.tbbh || role != model.ImageRoleAfter {
+			t.Errorf("parseImageName(%q) = %q, %q, %v", tt.tbbh+".jpg", tbbh, role, ok)
+		}
+	}
+}
+
+func TestUploadRejectsRoleSuffix(t *testing.T) {
+	// 校验在访问 Store 之前完成，所以这里不需要 Store
+	srv := NewImageService(nil, nil, t.TempDir(), t.TempDir())
+	for _, tbbh := range []string{"A1_after", "A1_field_2", "../A1"} {
+		_, err := srv.Upload(tbbh, model.ImageUploadInput{Role: model.ImageRoleAfter}, strings.NewReader(""), model.Operator{})
+		if !errors.Is(err, errcode.InvalidParams) {
+			t.Errorf("Upload(%q) err = %v, want errcode.InvalidParams", tbbh, err)
+		}
+	}
+}
@@ -0,0 +1,288 @@
+package service
+
+import (
+	"ProtectedArea/internal/model"
+	"ProtectedArea/pkg/errcode"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gabriel-vasile/mimetype"
+	"gorm.io/gorm"
+)
+
+// uploadImageTypes 允许上传的图片类型 (根据文件内容识别) -> 保存的后缀
+var uploadImageTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+}
+
+func (s *imageService) Upload(tbbh string, input model.ImageUploadInput, file io.Reader, op model.Operator) (*model.SpotImage, error) {
+	// 1. 校验图斑编号 (会拼接到文件名中，不能包含路径分隔符)，图斑必须存在
+	if !tbbhPattern.MatchString(tbbh) {
+		return nil, newValidationError("图斑编号格式不正确: %s", tbbh)
+	}
+	// 以角色后缀结尾的编号生成的文件名扫描时会被识别成其它图斑的图片
+	if hasImageRoleSuffix(tbbh) {
+		return nil, newValidationError("图斑编号以图片角色后缀结尾，无法保存图片: %s", tbbh)
+	}
+	spot, err := s.natureStore.GetSpot(tbbh, model.RegionScope{})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errcode.SpotNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// 2. 确定序号，同一角色和序号只能有一张图片 (替换请使用替换接口)
+	images, err := s.store.ListByTBBH(tbbh)
+	if err != nil {
+		return nil, err
+	}
+	seq := input.Seq
+	if seq == 0 {
+		seq = 1
+		if input.Role == model.ImageRoleField {
+			for _, img := range images {
+				if img.Role == model.ImageRoleField && img.Seq >= seq {
+					seq = img.Seq + 1
+				}
+			}
+		}
+	}
+	for _, img := range images {
+		if img.Role == input.Role && img.Seq == seq {
+			return nil, errcode.ImageExists.WithMessagef("图斑 %s 已有 %s 图片 (序号 %d)，请使用替换接口", tbbh, input.Role, seq)
+		}
+	}
+
+	// 3. 根据文件内容识别类型，写入文件
+	ext, content, err := detectImageType(file)
+	if err != nil {
+		return nil, err
+	}
+	img := &model.SpotImage{TBBH: tbbh, Role: input.Role, Seq: seq, Path: imageFileName(tbbh, input.Role, seq) + ext}
+	target := s.absPath(img.Path)
+	if _, err := os.Stat(target); err == nil {
+		return nil, errcode.ImageExists.WithMessagef("文件 %s 已存在但不在图片索引中，请先重新扫描图片目录", img.Path)
+	}
+	sum, err := s.writeImage(target, content, img)
+	if err != nil {
+		return nil, err
+	}
+	img.CaptureDate = captureDate(img, spot)
+
+	// 4. 写入索引和变更记录，失败时删除刚写入的文件
+	audit := newImageAudit(model.ImageActionUpload, img, sum, op)
+	if err := s.store.Create(img, audit); err != nil {
+		removeImageFile(target)
+		return nil, err
+	}
+	return img, nil
+}
+
+func (s *imageService) Replace(id uint, file io.Reader, op model.Operator) (*model.SpotImage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	img, err := s.store.GetByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errcode.ImageNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// 1. 新文件沿用原来的文件名，只根据内容调整后缀 (例如用 PNG 替换 JPEG)
+	ext, content, err := detectImageType(file)
+	if err != nil {
+		return nil, err
+	}
+	oldPath := img.Path
+	img.Path = strings.TrimSuffix(oldPath, path.Ext(oldPath)) + ext
+	target := s.absPath(img.Path)
+	if img.Path != oldPath {
+		if _, err := os.Stat(target); err == nil {
+			return nil, errcode.ImageExists.WithMessagef("文件 %s 已存在，请先重新扫描图片目录", img.Path)
+		}
+	}
+
+	// 2. 新内容先写入临时文件，索引更新成功之前不动旧文件
+	tmp, sum, err := stageImage(filepath.Dir(target), content, img)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp) // 重命名成功后删除会失败，可以忽略
+	spot, err := s.natureStore.GetSpot(img.TBBH, model.RegionScope{})
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	img.CaptureDate = captureDate(img, spot)
+
+	// 3. 更新索引和变更记录，失败时旧文件和索引保持不变
+	audit := newImageAudit(model.ImageActionReplace, img, sum, op)
+	audit.OldPath = oldPath
+	if err := s.store.Update(img, audit); err != nil {
+		return nil, err
+	}
+
+	// 4. 用新文件替换 (同名时原子覆盖旧文件)；后缀变化时删除旧文件
+	if err := os.Rename(tmp, target); err != nil {
+		// 索引已经是新文件的信息，重新扫描图片目录时会按磁盘上的文件修正
+		return nil, fmt.Errorf("替换图片文件 %s 失败 (索引已更新，请重新扫描图片目录): %w", img.Path, err)
+	}
+	if img.Path != oldPath {
+		removeImageFile(s.absPath(oldPath))
+	}
+	return img, nil
+}
+
+func (s *imageService) Delete(id uint, op model.Operator) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	img, err := s.store.GetByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errcode.ImageNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	audit := newImageAudit(model.ImageActionDelete, img, "", op)
+	audit.Size = 0
+	if err := s.store.Delete(img, audit); err != nil {
+		return err
+	}
+	removeImageFile(s.absPath(img.Path))
+	return nil
+}
+
+func (s *imageService) ListAudits(req model.ImageAuditQueryRequest) (map[string]interface{}, error) {
+	list, total, err := s.store.ListAudits(strings.TrimSpace(req.TBBH), req.Page, req.PageSize)
+	if err != nil {
+		return nil, err
+	}
+	return buildPagedResponse(list, total, req.Page, req.PageSize), nil
+}
+
+// absPath 索引中的相对路径 -> 文件路径
+func (s *imageService) absPath(rel string) string {
+	return filepath.Join(s.root, filepath.FromSlash(rel))
+}
+
+// writeImage 把 content 写入 target: 先写临时文件，确认是可以解析的图片后再重命名，读者不会看到写了一半的文件
+// 同时填充 img 的格式、尺寸、大小和修改时间，返回内容的 SHA-256
+func (s *imageService) writeImage(target string, content io.Reader, img *model.SpotImage) (string, error) {
+	tmp, sum, err := stageImage(filepath.Dir(target), content, img)
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp) // 重命名成功后删除会失败，可以忽略
+
+	if err := os.Rename(tmp, target); err != nil {
+		return "", err
+	}
+	return sum, nil
+}
+
+// stageImage 把 content 写入 dir 下的临时文件 (以 . 开头，扫描时会忽略) 并确认是可以解析的图片，
+// 同时填充 img 的格式、尺寸、大小和修改时间 (重命名不会改变)，返回临时文件路径和内容的 SHA-256
+// 调用方负责重命名或删除临时文件；出错时临时文件已被删除
+func stageImage(dir string, content io.Reader, img *model.SpotImage) (tmpPath, sum string, err error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", "", err
+	}
+	tmp, err := os.CreateTemp(dir, ".upload-*.tmp")
+	if err != nil {
+		return "", "", err
+	}
+	defer func() {
+		if err != nil {
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(tmp, h), content); err != nil {
+		tmp.Close()
+		return "", "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", "", err
+	}
+
+	if err := readImageConfig(tmp.Name(), img); err != nil {
+		return "", "", newValidationError("图片文件无法解析: %v", err)
+	}
+	info, err := os.Stat(tmp.Name())
+	if err != nil {
+		return "", "", err
+	}
+	img.Size, img.ModTime = info.Size(), info.ModTime().Truncate(time.Second)
+	return tmp.Name(), hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// detectImageType 根据文件头识别图片类型，返回保存的后缀和完整内容的 Reader
+func detectImageType(file io.Reader) (string, io.Reader, error) {
+	head := make([]byte, 3072) // mimetype 默认只读取前 3KB
+	n, err := io.ReadFull(file, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return "", nil, err
+	}
+	if n == 0 {
+		return "", nil, newValidationError("上传的文件为空")
+	}
+
+	mt := mimetype.Detect(head[:n])
+	ext, ok := uploadImageTypes[mt.String()]
+	if !ok {
+		return "", nil, newValidationError("只支持 JPEG/PNG 图片，上传的文件类型为 %s", mt.String())
+	}
+	return ext, io.MultiReader(bytes.NewReader(head[:n]), file), nil
+}
+
+// imageFileName 上传图片的文件名 (不含后缀)，与扫描时的命名规则一致
+func imageFileName(tbbh, role string, seq int) string {
+	if role != model.ImageRoleField && seq == 1 {
+		return tbbh + "_" + role
+	}
+	return tbbh + "_" + role + "_" + strconv.Itoa(seq)
+}
+
+// newImageAudit 根据图片和操作人生成变更记录
+func newImageAudit(action string, img *model.SpotImage, sum string, op model.Operator) *model.ImageAudit {
+	return &model.ImageAudit{
+		ImageID:    img.ID,
+		TBBH:       img.TBBH,
+		Action:     action,
+		Role:       img.Role,
+		Seq:        img.Seq,
+		Path:       img.Path,
+		Size:       img.Size,
+		SHA256:     sum,
+		OperatorID: op.UserID,
+		Operator:   op.Username,
+		ClientIP:   op.IP,
+	}
+}
+
+// removeImageFile 删除图片文件，文件不存在时忽略；其它错误只记录日志 (索引已经更新)
+func removeImageFile(path string) {
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Printf("删除图片文件 %s 失败: %v", path, err)
+	}
+}
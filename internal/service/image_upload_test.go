@@ -0,0 +1,220 @@
+package service
+
+import (
+	"ProtectedArea/internal/model"
+	"ProtectedArea/internal/store"
+	"ProtectedArea/pkg/errcode"
+	"bytes"
+	"errors"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func encodePNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, w, h))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func encodeJPEG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, image.NewRGBA(image.Rect(0, 0, w, h)), nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestImageFileName(t *testing.T) {
+	tests := []struct {
+		tbbh, role string
+		seq        int
+		want       string
+	}{
+		{tbbh: "A1", role: model.ImageRoleBefore, seq: 1, want: "A1_before"},
+		{tbbh: "A1", role: model.ImageRoleAfter, seq: 2, want: "A1_after_2"},
+		{tbbh: "A1", role: model.ImageRoleField, seq: 1, want: "A1_field_1"},
+		{tbbh: "110109_NR_001", role: model.ImageRoleField, seq: 12, want: "110109_NR_001_field_12"},
+	}
+	for _, tt := range tests {
+		got := imageFileName(tt.tbbh, tt.role, tt.seq)
+		if got != tt.want {
+			t.Errorf("imageFileName(%q, %q, %d) = %q, want %q", tt.tbbh, tt.role, tt.seq, got, tt.want)
+		}
+		// 与扫描时的命名规则一致
+		tbbh, role, seq, ok := parseImageName(got + ".jpg")
+		if !ok || tbbh != tt.tbbh || role != tt.role || seq != tt.seq {
+			t.Errorf("parseImageName(%q) = %q, %q, %d, %v", got+".jpg", tbbh, role, seq, ok)
+		}
+	}
+}
+
+func TestDetectImageType(t *testing.T) {
+	pngData := encodePNG(t, 4, 4)
+	tests := []struct {
+		name    string
+		data    []byte
+		wantExt string
+		wantErr bool
+	}{
+		{name: "PNG", data: pngData, wantExt: ".png"},
+		{name: "JPEG", data: encodeJPEG(t, 4, 4), wantExt: ".jpg"},
+		{name: "空文件", data: nil, wantErr: true},
+		{name: "GIF", data: []byte("GIF89a\x01\x00\x01\x00\x00\x00\x00;"), wantErr: true},
+		{name: "伪装成图片的文本", data: []byte("<html>not an image</html>"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ext, content, err := detectImageType(bytes.NewReader(tt.data))
+			if tt.wantErr {
+				if !errors.Is(err, errcode.InvalidParams) {
+					t.Fatalf("err = %v, want errcode.InvalidParams", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			if ext != tt.wantExt {
+				t.Errorf("ext = %q, want %q", ext, tt.wantExt)
+			}
+			// 已读取的文件头要拼回内容中
+			all, _ := io.ReadAll(content)
+			if !bytes.Equal(all, tt.data) {
+				t.Errorf("content length = %d, want %d", len(all), len(tt.data))
+			}
+		})
+	}
+}
+
+func TestStageImage(t *testing.T) {
+	dir := t.TempDir()
+
+	img := &model.SpotImage{}
+	tmp, sum, err := stageImage(dir, bytes.NewReader(encodePNG(t, 30, 20)), img)
+	if err != nil {
+		t.Fatalf("stageImage: %v", err)
+	}
+	// 临时文件以 . 开头，扫描时会被忽略
+	if !strings.HasPrefix(filepath.Base(tmp), ".") || filepath.Dir(tmp) != dir {
+		t.Errorf("tmp path = %q", tmp)
+	}
+	if img.Format != "png" || img.Width != 30 || img.Height != 20 || img.Size == 0 || len(sum) != 64 {
+		t.Errorf("img = %+v, sum = %q", img, sum)
+	}
+
+	// 无法解析的内容: 返回校验错误并删除临时文件
+	if _, _, err := stageImage(dir, strings.NewReader("\x89PNG\r\n\x1a\nbroken"), &model.SpotImage{}); !errors.Is(err, errcode.InvalidParams) {
+		t.Errorf("broken image err = %v, want errcode.InvalidParams", err)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("files in dir = %d, want only the first staged file", len(entries))
+	}
+}
+
+// fakeReplaceStore 替换时使用的图片索引
+type fakeReplaceStore struct {
+	store.ImageStore
+	img       model.SpotImage
+	updateErr error
+	updated   *model.SpotImage
+}
+
+func (f *fakeReplaceStore) GetByID(id uint) (*model.SpotImage, error) {
+	img := f.img
+	return &img, nil
+}
+
+func (f *fakeReplaceStore) Update(img *model.SpotImage, audit *model.ImageAudit) error {
+	if f.updateErr != nil {
+		return f.updateErr
+	}
+	f.updated = img
+	return nil
+}
+
+// fakeSpotStore 查询图斑时返回不存在
+type fakeSpotStore struct {
+	store.NatureStore
+}
+
+func (fakeSpotStore) GetSpot(tbbh string, scope model.RegionScope) (*model.NatureData, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+
+func TestReplace(t *testing.T) {
+	oldContent := encodeJPEG(t, 8, 8)
+	newContent := encodePNG(t, 16, 16)
+
+	tests := []struct {
+		name      string
+		updateErr error
+		wantPath  string // 替换后应存在的文件
+		wantGone  string // 替换后应被删除的文件
+		wantErr   bool
+	}{
+		{name: "索引更新成功后替换文件", wantPath: "A1_after.png", wantGone: "A1_after.jpg"},
+		{name: "索引更新失败时保留旧文件", updateErr: errors.New("deadlock"), wantPath: "A1_after.jpg", wantGone: "A1_after.png", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			if err := os.WriteFile(filepath.Join(root, "A1_after.jpg"), oldContent, 0o644); err != nil {
+				t.Fatal(err)
+			}
+			fake := &fakeReplaceStore{
+				img:       model.SpotImage{ID: 1, TBBH: "A1", Role: model.ImageRoleAfter, Seq: 1, Path: "A1_after.jpg"},
+				updateErr: tt.updateErr,
+			}
+			srv := NewImageService(fake, fakeSpotStore{}, root, t.TempDir())
+
+			img, err := srv.Replace(1, bytes.NewReader(newContent), model.Operator{Username: "admin"})
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("want error")
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("unexpected err: %v", err)
+				}
+				if img.Path != "A1_after.png" || img.Width != 16 || fake.updated == nil {
+					t.Errorf("img = %+v", img)
+				}
+			}
+
+			if _, err := os.Stat(filepath.Join(root, tt.wantPath)); err != nil {
+				t.Errorf("%s: %v", tt.wantPath, err)
+			}
+			if _, err := os.Stat(filepath.Join(root, tt.wantGone)); !os.IsNotExist(err) {
+				t.Errorf("%s should not exist, stat err = %v", tt.wantGone, err)
+			}
+			if tt.wantErr {
+				data, _ := os.ReadFile(filepath.Join(root, "A1_after.jpg"))
+				if !bytes.Equal(data, oldContent) {
+					t.Errorf("old file was modified")
+				}
+			}
+			// 不留下临时文件
+			entries, _ := os.ReadDir(root)
+			if len(entries) != 1 {
+				var names []string
+				for _, e := range entries {
+					names = append(names, e.Name())
+				}
+				t.Errorf("files in root = %q, want one", names)
+			}
+		})
+	}
+}
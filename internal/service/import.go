@@ -0,0 +1,377 @@
+package service
+
+import (
+	"ProtectedArea/internal/model"
+	"ProtectedArea/internal/store"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// importBatchSize 每个事务写入的行数
+const importBatchSize = 500
+
+// tbbhPattern 合法的图斑编号: 字母、数字、下划线和中划线
+var tbbhPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// hasImageRoleSuffix 图斑编号是否以图片角色后缀 (_before、_after、_field，可带 _N 序号) 结尾
+// 这样的编号与图片文件名 TBBH_角色[_序号] 有歧义 (例如 A_after.jpg)，导入和上传时拒绝
+func hasImageRoleSuffix(tbbh string) bool {
+	return imageNamePattern.MatchString(tbbh)
+}
+
+// yearPattern 四位年份
+var yearPattern = regexp.MustCompile(`^\d{4}$`)
+
+type ImportService interface {
+	// ImportSpots 解析 CSV/XLSX 文件 (根据 fileName 后缀判断格式)，校验后按 TBBH 写入 nature_data
+	// 返回的 error 只表示整个文件无法处理 (格式错误、缺少必需列等)，单行错误记录在结果报告中
+	ImportSpots(fileName string, r io.Reader, opts model.ImportOptions) (*model.ImportResult, error)
+}
+
+type importService struct {
+	store store.NatureStore
+}
+
+func NewImportService(s store.NatureStore) ImportService {
+	return &importService{store: s}
+}
+
+// importRow 解析后的一行数据
+type importRow struct {
+	line int // 文件中的行号
+	data model.NatureData
+}
+
+func (s *importService) ImportSpots(fileName string, r io.Reader, opts model.ImportOptions) (*model.ImportResult, error) {
+	// 1. 读取原始表格 (第一行为表头)
+	records, err := readTable(fileName, r)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, newValidationError("文件为空")
+	}
+
+	// 2. 识别表头
+	columns, err := matchHeader(records[0])
+	if err != nil {
+		return nil, err
+	}
+	updateColumns := importUpdateColumns(columns, opts)
+
+	result := &model.ImportResult{FileName: fileName, DryRun: opts.DryRun, Errors: []model.ImportRowError{}}
+
+	// 3. 逐行解析和校验
+	var valid []importRow
+	seen := make(map[string]int) // TBBH -> 首次出现的行号，用于发现文件内重复
+	for i, record := range records[1:] {
+		line := i + 2
+		if isBlankRecord(record) {
+			continue
+		}
+		result.TotalRows++
+
+		data, rowErrs := parseImportRow(columns, record, opts)
+		if first, ok := seen[data.TBBH]; ok && data.TBBH != "" {
+			rowErrs = append(rowErrs, fmt.Sprintf("图斑编号与第 %d 行重复", first))
+		} else if data.TBBH != "" {
+			seen[data.TBBH] = line
+		}
+
+		if len(rowErrs) > 0 {
+			result.Errors = append(result.Errors, model.ImportRowError{Row: line, TBBH: data.TBBH, Errors: rowErrs})
+			continue
+		}
+		valid = append(valid, importRow{line: line, data: data})
+	}
+
+	// 4. 分批入库，每批一个事务；某批失败时整批记为失败，不影响其它批次
+	if !opts.DryRun {
+		for start := 0; start < len(valid); start += importBatchSize {
+			end := min(start+importBatchSize, len(valid))
+			batch := valid[start:end]
+
+			spots := make([]model.NatureData, len(batch))
+			for i, row := range batch {
+				spots[i] = row.data
+			}
+			if err := s.store.UpsertSpots(spots, updateColumns); err != nil {
+				for _, row := range batch {
+					result.Errors = append(result.Errors, model.ImportRowError{
+						Row:    row.line,
+						TBBH:   row.data.TBBH,
+						Errors: []string{"写入数据库失败: " + err.Error()},
+					})
+				}
+				continue
+			}
+			result.Succeeded += len(batch)
+		}
+	} else {
+		result.Succeeded = len(valid)
+	}
+
+	result.Failed = result.TotalRows - result.Succeeded
+	return result, nil
+}
+
+// readTable 读取 CSV 或 XLSX (第一个工作表) 的全部行
+func readTable(fileName string, r io.Reader) ([][]string, error) {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".csv":
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, fmt.Errorf("读取文件失败: %w", err)
+		}
+		// 去掉 Excel 另存为 CSV 时带上的 UTF-8 BOM
+		data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+		reader := csv.NewReader(bytes.NewReader(data))
+		reader.FieldsPerRecord = -1 // 允许各行列数不一致，缺失的列按空值处理
+		reader.TrimLeadingSpace = true
+		records, err := reader.ReadAll()
+		if err != nil {
+			return nil, newValidationError("CSV 格式错误: %v", err)
+		}
+		return records, nil
+
+	case ".xlsx":
+		f, err := excelize.OpenReader(r)
+		if err != nil {
+			return nil, newValidationError("无法解析 Excel 文件: %v", err)
+		}
+		defer f.Close()
+
+		sheets := f.GetSheetList()
+		if len(sheets) == 0 {
+			return nil, newValidationError("Excel 文件中没有工作表")
+		}
+		records, err := f.GetRows(sheets[0])
+		if err != nil {
+			return nil, newValidationError("读取工作表 %s 失败: %v", sheets[0], err)
+		}
+		return records, nil
+
+	default:
+		return nil, newValidationError("不支持的文件格式: %s (仅支持 .csv 和 .xlsx)", filepath.Ext(fileName))
+	}
+}
+
+// matchHeader 把表头映射为数据库列名，支持列名 (不区分大小写) 或中文含义
+// 返回值下标与文件列一一对应，表头为空的列为空字符串 (忽略)；存在无法识别的表头时返回错误，
+// 避免拼错的列被静默丢弃
+func matchHeader(header []string) ([]string, error) {
+	lookup := make(map[string]string)
+	for _, f := range model.NatureFields {
+		lookup[strings.ToUpper(f.Column)] = f.Column
+		lookup[f.Label] = f.Column
+	}
+
+	columns := make([]string, len(header))
+	found := make(map[string]bool)
+	var unknown []string
+	for i, h := range header {
+		h = strings.TrimSpace(strings.TrimPrefix(h, "\ufeff"))
+		if h == "" {
+			continue
+		}
+		col, ok := lookup[strings.ToUpper(h)]
+		if !ok {
+			unknown = append(unknown, h)
+			continue
+		}
+		if found[col] {
+			return nil, newValidationError("表头中 %s 列重复", col)
+		}
+		found[col] = true
+		columns[i] = col
+	}
+
+	if len(unknown) > 0 {
+		return nil, newValidationError("无法识别的列: %s (请使用 nature_data 的列名或中文名)", strings.Join(unknown, ", "))
+	}
+
+	// 必需列
+	for _, col := range []string{"TBBH", "BHDL"} {
+		if !found[col] {
+			return nil, newValidationError("缺少必需列: %s", col)
+		}
+	}
+	return columns, nil
+}
+
+// importUpdateColumns 更新已有图斑时写入的列: 文件中出现的列，加上由导入选项补全的 year/PC
+// 文件中没有的列 (例如只导入核实结果时的坐标、原报字段) 保持数据库中的原值
+func importUpdateColumns(columns []string, opts model.ImportOptions) []string {
+	var result []string
+	has := make(map[string]bool)
+	for _, col := range columns {
+		if col != "" {
+			result = append(result, col)
+			has[col] = true
+		}
+	}
+	if !has["year"] && opts.Year != "" {
+		result = append(result, "year")
+	}
+	if !has["PC"] && opts.PC != "" {
+		result = append(result, "PC")
+	}
+	return result
+}
+
+// isBlankRecord 判断是否为空行
+func isBlankRecord(record []string) bool {
+	for _, v := range record {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// parseImportRow 把一行数据转换为 NatureData，并返回该行的全部校验错误
+func parseImportRow(columns []string, record []string, opts model.ImportOptions) (model.NatureData, []string) {
+	var data model.NatureData
+	var errs []string
+
+	for i, col := range columns {
+		if col == "" || i >= len(record) {
+			continue
+		}
+		if err := setNatureField(&data, col, strings.TrimSpace(record[i])); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	// 使用导入选项补全缺失的年份和批次
+	if data.Year == "" {
+		data.Year = opts.Year
+	}
+	if data.PC == "" {
+		data.PC = opts.PC
+	}
+
+	// 业务校验
+	switch {
+	case data.TBBH == "":
+		errs = append(errs, "图斑编号(TBBH)不能为空")
+	case !tbbhPattern.MatchString(data.TBBH):
+		errs = append(errs, "图斑编号(TBBH)只能包含字母、数字、下划线和中划线")
+	case hasImageRoleSuffix(data.TBBH):
+		errs = append(errs, "图斑编号(TBBH)不能以 _before、_after、_field (可带 _序号) 结尾，会与图片文件名冲突")
+	}
+	if data.BHDL == "" {
+		errs = append(errs, "变化地类(BHDL)不能为空")
+	}
+	if !yearPattern.MatchString(data.Year) {
+		errs = append(errs, fmt.Sprintf("年份(year)必须是四位数字: %q", data.Year))
+	}
+	if data.X < -180 || data.X > 180 {
+		errs = append(errs, fmt.Sprintf("经度(X)超出范围 [-180, 180]: %v", data.X))
+	}
+	if data.Y < -90 || data.Y > 90 {
+		errs = append(errs, fmt.Sprintf("纬度(Y)超出范围 [-90, 90]: %v", data.Y))
+	}
+	if data.BHMJ < 0 {
+		errs = append(errs, fmt.Sprintf("图斑面积(BHMJ)不能为负数: %v", data.BHMJ))
+	}
+	if data.SFCXBH != 0 && data.SFCXBH != 1 {
+		errs = append(errs, fmt.Sprintf("是否重复变化(SFCXBH)只能是 0 或 1: %d", data.SFCXBH))
+	}
+
+	return data, errs
+}
+
+// setNatureField 按列名给 NatureData 的字段赋值，数值列解析失败时返回错误
+func setNatureField(d *model.NatureData, col, val string) error {
+	parseFloat := func(dst *float64) error {
+		if val == "" {
+			return nil
+		}
+		f, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return fmt.Errorf("%s 不是合法数字: %q", col, val)
+		}
+		*dst = f
+		return nil
+	}
+
+	switch col {
+	case "TBBH":
+		d.TBBH = val
+	case "BHDL":
+		d.BHDL = val
+	case "QLX":
+		d.QLX = val
+	case "HLX":
+		d.HLX = val
+	case "X":
+		return parseFloat(&d.X)
+	case "Y":
+		return parseFloat(&d.Y)
+	case "BHMJ":
+		return parseFloat(&d.BHMJ)
+	case "THBHDMC":
+		d.THBHDMC = val
+	case "BHDLX":
+		d.BHDLX = val
+	case "PC":
+		d.PC = val
+	case "BQSJ":
+		d.BQSJ = val
+	case "SQSJ":
+		d.SQSJ = val
+	case "THXDM":
+		d.THXDM = val
+	case "THSHENG":
+		d.THSHENG = val
+	case "THSHI":
+		d.THSHI = val
+	case "THXIAN":
+		d.THXIAN = val
+	case "SFCXBH":
+		if val == "" {
+			return nil
+		}
+		n, err := strconv.Atoi(val)
+		if err != nil {
+			return fmt.Errorf("SFCXBH 不是合法整数: %q", val)
+		}
+		d.SFCXBH = n
+	case "SQTBBH":
+		d.SQTBBH = val
+	case "THBZ":
+		d.THBZ = val
+	case "YBBHDMC":
+		d.YBBHDMC = val
+	case "YBBHDLXBM":
+		d.YBBHDLXBM = val
+	case "YBSHENG":
+		d.YBSHENG = val
+	case "YBSHI":
+		d.YBSHI = val
+	case "YBXIAN":
+		d.YBXIAN = val
+	case "YBXBM":
+		d.YBXBM = val
+	case "YBBZ1":
+		d.YBBZ1 = val
+	case "YBBZ2":
+		d.YBBZ2 = val
+	case "year":
+		d.Year = val
+	default:
+		return errors.New("未知列: " + col)
+	}
+	return nil
+}
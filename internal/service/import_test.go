@@ -0,0 +1,147 @@
+package service
+
+import (
+	"ProtectedArea/internal/model"
+	"ProtectedArea/pkg/errcode"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMatchHeader(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  []string
+		want    []string
+		wantErr string // 为空表示不应出错
+	}{
+		{
+			name:   "列名不区分大小写",
+			header: []string{"tbbh", "Bhdl", "YEAR"},
+			want:   []string{"TBBH", "BHDL", "year"},
+		},
+		{
+			name:   "中文表头和 BOM",
+			header: []string{"\ufeff图斑编号", " 变化地类 ", "经度"},
+			want:   []string{"TBBH", "BHDL", "X"},
+		},
+		{
+			name:   "空表头的列忽略",
+			header: []string{"TBBH", "", "BHDL"},
+			want:   []string{"TBBH", "", "BHDL"},
+		},
+		{
+			name:    "无法识别的表头",
+			header:  []string{"TBBH", "BHDL", "经 度", "remark"},
+			wantErr: "经 度, remark",
+		},
+		{
+			name:    "重复列",
+			header:  []string{"TBBH", "BHDL", "图斑编号"},
+			wantErr: "TBBH 列重复",
+		},
+		{
+			name:    "缺少必需列",
+			header:  []string{"TBBH", "X"},
+			wantErr: "缺少必需列: BHDL",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := matchHeader(tt.header)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want containing %q", err, tt.wantErr)
+				}
+				if !errors.Is(err, errcode.InvalidParams) {
+					t.Errorf("err = %v, want errcode.InvalidParams", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("columns = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImportUpdateColumns(t *testing.T) {
+	columns := []string{"TBBH", "", "BHDL", "X"}
+
+	got := importUpdateColumns(columns, model.ImportOptions{})
+	if want := []string{"TBBH", "BHDL", "X"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("without options = %q, want %q", got, want)
+	}
+
+	// 由导入选项补全的 year/PC 也要更新
+	got = importUpdateColumns(columns, model.ImportOptions{Year: "2024", PC: "202401"})
+	if want := []string{"TBBH", "BHDL", "X", "year", "PC"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("with options = %q, want %q", got, want)
+	}
+}
+
+func TestParseImportRow(t *testing.T) {
+	columns := []string{"TBBH", "BHDL", "X", "Y", "BHMJ", "SFCXBH", "year", "PC"}
+
+	tests := []struct {
+		name     string
+		record   []string
+		opts     model.ImportOptions
+		want     model.NatureData
+		wantErrs []string // 每个错误信息应包含的片段
+	}{
+		{
+			name:   "合法行",
+			record: []string{"110109202202NR001", "资源损毁", "116.1", "39.9", "1.5", "1", "2024", "202401"},
+			want:   model.NatureData{TBBH: "110109202202NR001", BHDL: "资源损毁", X: 116.1, Y: 39.9, BHMJ: 1.5, SFCXBH: 1, Year: "2024", PC: "202401"},
+		},
+		{
+			name:   "使用导入选项补全年份和批次，缺失的列为零值",
+			record: []string{"A-1", "恢复治理", "", ""},
+			opts:   model.ImportOptions{Year: "2023", PC: "202302"},
+			want:   model.NatureData{TBBH: "A-1", BHDL: "恢复治理", Year: "2023", PC: "202302"},
+		},
+		{
+			name:     "必填项和年份",
+			record:   []string{"", "", "", "", "", "", "24"},
+			wantErrs: []string{"TBBH", "BHDL", "年份"},
+		},
+		{
+			name:     "非法图斑编号",
+			record:   []string{"A/1", "资源损毁", "", "", "", "", "2024"},
+			wantErrs: []string{"只能包含字母"},
+		},
+		{
+			name:     "图斑编号以图片角色后缀结尾",
+			record:   []string{"A1_after_2", "资源损毁", "", "", "", "", "2024"},
+			wantErrs: []string{"不能以 _before、_after、_field"},
+		},
+		{
+			name:     "数值格式和范围",
+			record:   []string{"A1", "资源损毁", "abc", "91", "-1", "2", "2024"},
+			wantErrs: []string{"X 不是合法数字", "纬度(Y)超出范围", "不能为负数", "只能是 0 或 1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, errs := parseImportRow(columns, tt.record, tt.opts)
+			if len(errs) != len(tt.wantErrs) {
+				t.Fatalf("errs = %q, want %d errors", errs, len(tt.wantErrs))
+			}
+			for i, want := range tt.wantErrs {
+				if !strings.Contains(errs[i], want) {
+					t.Errorf("errs[%d] = %q, want containing %q", i, errs[i], want)
+				}
+			}
+			if len(tt.wantErrs) == 0 && got != tt.want {
+				t.Errorf("data = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"ProtectedArea/internal/model"
+	"ProtectedArea/pkg/errcode"
+	"errors"
+	"sort"
+
+	"gorm.io/gorm"
+)
+
+// maxLineageDepth 向前、向后各自最多追溯的期数，防止脏数据形成过长或循环的链条
+const maxLineageDepth = 20
+
+// GetSpotLineage 图斑演变链: 沿 SQTBBH 向前追溯上期图斑，再反向查找以本图斑为上期的后续图斑
+// 后续图斑可能有多个 (一个图斑在下一期被拆分)，按层逐级查找
+func (s *natureService) GetSpotLineage(tbbh string, userScope model.RegionScope) (*model.SpotLineage, error) {
+	// 1. 查询图斑本身
+	spot, err := s.store.GetSpot(tbbh, userScope)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errcode.SpotNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := &model.SpotLineage{TBBH: spot.TBBH, Nodes: []model.LineageNode{newLineageNode(spot, 0)}}
+	visited := map[string]bool{spot.TBBH: true}
+
+	// 2. 向前追溯: 每期只有一个上期图斑
+	prev := spot.SQTBBH
+	for depth := -1; prev != "" && !visited[prev]; depth-- {
+		if -depth > maxLineageDepth {
+			result.Truncated = true
+			break
+		}
+		p, err := s.store.GetSpot(prev, userScope)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			break // 上期图斑不在库中或不在数据范围内
+		}
+		if err != nil {
+			return nil, err
+		}
+		visited[p.TBBH] = true
+		result.Nodes = append(result.Nodes, newLineageNode(p, depth))
+		prev = p.SQTBBH
+	}
+
+	// 3. 向后查找: 逐层查询以上一层为上期的图斑
+	level := []string{spot.TBBH}
+	for depth := 1; len(level) > 0; depth++ {
+		if depth > maxLineageDepth {
+			result.Truncated = true
+			break
+		}
+		next, err := s.store.GetSuccessorSpots(level, userScope)
+		if err != nil {
+			return nil, err
+		}
+		level = level[:0]
+		for i := range next {
+			if visited[next[i].TBBH] {
+				continue
+			}
+			visited[next[i].TBBH] = true
+			result.Nodes = append(result.Nodes, newLineageNode(&next[i], depth))
+			level = append(level, next[i].TBBH)
+		}
+	}
+
+	// 4. 按代数从早到晚排序
+	sort.SliceStable(result.Nodes, func(i, j int) bool {
+		return result.Nodes[i].Depth < result.Nodes[j].Depth
+	})
+	return result, nil
+}
+
+func newLineageNode(d *model.NatureData, depth int) model.LineageNode {
+	return model.LineageNode{
+		TBBH:    d.TBBH,
+		SQTBBH:  d.SQTBBH,
+		Year:    d.Year,
+		PC:      d.PC,
+		BHDL:    d.BHDL,
+		QLX:     d.QLX,
+		HLX:     d.HLX,
+		BHMJ:    d.BHMJ,
+		THBHDMC: d.THBHDMC,
+		SFCXBH:  d.SFCXBH,
+		Depth:   depth,
+	}
+}
+
+// maxRepeatChangePageSize 重复变化图斑明细每页最多条数
+const maxRepeatChangePageSize = 1000
+
+// GetRepeatChanges 重复变化报告: 按保护地汇总 SFCXBH=1 的图斑，并统计上期图斑到本期的变化地类，
+// 用于判断损毁图斑是否已经恢复，还是再次损毁；汇总在数据库中完成，图斑明细使用 GetRepeatChangeSpots 分页查询
+func (s *natureService) GetRepeatChanges(req model.NatureQueryRequest) ([]model.RepeatChangeGroup, error) {
+	// 1. 按 保护地、上期地类、本期地类 统计 (结果已按保护地名称排序)
+	stats, err := s.store.GetRepeatChangeStats(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. 按保护地合并
+	groups := []model.RepeatChangeGroup{}
+	for _, stat := range stats {
+		if len(groups) == 0 || groups[len(groups)-1].Name != stat.Name {
+			groups = append(groups, model.RepeatChangeGroup{Name: stat.Name, Transitions: map[string]int64{}})
+		}
+		g := &groups[len(groups)-1]
+
+		prevBHDL := stat.PrevBHDL
+		if prevBHDL == "" {
+			prevBHDL = "未知"
+		}
+		g.Count += stat.Count
+		g.Area += stat.Area
+		g.Transitions[prevBHDL+"→"+stat.BHDL] += stat.Count
+	}
+
+	// 3. 重复变化多的保护地排在前面
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].Count > groups[j].Count
+	})
+	return groups, nil
+}
+
+func (s *natureService) GetRepeatChangeSpots(req model.RepeatChangeSpotsRequest) (map[string]interface{}, error) {
+	if req.Page < 1 || req.PageSize < 1 || req.PageSize > maxRepeatChangePageSize {
+		return nil, newValidationError("page 必须大于 0，page_size 必须在 1-%d 之间", maxRepeatChangePageSize)
+	}
+	orderBy, err := buildOrderBy(req.Sort, req.Order, spotSortColumns, "TBBH")
+	if err != nil {
+		return nil, err
+	}
+	req.OrderBy = orderBy
+
+	// 1. 当前页的重复变化图斑
+	spots, total, err := s.store.GetRepeatChangeSpots(req.NatureQueryRequest, req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. 批量查询上期图斑
+	var prevIDs []string
+	for _, spot := range spots {
+		if spot.SQTBBH != "" {
+			prevIDs = append(prevIDs, spot.SQTBBH)
+		}
+	}
+	prevSpots, err := s.store.GetSpotsByTBBH(prevIDs, req.UserScope)
+	if err != nil {
+		return nil, err
+	}
+	prevMap := make(map[string]*model.NatureData, len(prevSpots))
+	for i := range prevSpots {
+		prevMap[prevSpots[i].TBBH] = &prevSpots[i]
+	}
+
+	list := make([]model.RepeatChangeSpot, len(spots))
+	for i, spot := range spots {
+		list[i] = model.RepeatChangeSpot{
+			TBBH:   spot.TBBH,
+			BHDL:   spot.BHDL,
+			QLX:    spot.QLX,
+			HLX:    spot.HLX,
+			BHMJ:   spot.BHMJ,
+			SQTBBH: spot.SQTBBH,
+		}
+		if p, ok := prevMap[spot.SQTBBH]; ok {
+			list[i].PrevYear = p.Year
+			list[i].PrevBHDL = p.BHDL
+		}
+	}
+	return buildPagedResponse(list, total, req.Page, req.PageSize), nil
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"ProtectedArea/internal/model"
+	"ProtectedArea/internal/store"
+	"ProtectedArea/pkg/errcode"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// fakeRepeatChangeStore 返回固定的重复变化统计和图斑
+type fakeRepeatChangeStore struct {
+	store.NatureStore
+	stats []model.RepeatChangeTransitionStat
+	spots []model.NatureData
+	prev  []model.NatureData
+
+	req  model.NatureQueryRequest
+	name string
+}
+
+func (f *fakeRepeatChangeStore) GetRepeatChangeStats(req model.NatureQueryRequest) ([]model.RepeatChangeTransitionStat, error) {
+	return f.stats, nil
+}
+
+func (f *fakeRepeatChangeStore) GetRepeatChangeSpots(req model.NatureQueryRequest, name string) ([]model.NatureData, int64, error) {
+	f.req, f.name = req, name
+	return f.spots, int64(len(f.spots)), nil
+}
+
+func (f *fakeRepeatChangeStore) GetSpotsByTBBH(ids []string, scope model.RegionScope) ([]model.NatureData, error) {
+	return f.prev, nil
+}
+
+func TestGetRepeatChanges(t *testing.T) {
+	fake := &fakeRepeatChangeStore{stats: []model.RepeatChangeTransitionStat{
+		{Name: "A保护区", PrevBHDL: "资源损毁", BHDL: "恢复治理", Count: 1, Area: 2},
+		{Name: "B保护区", PrevBHDL: "", BHDL: "资源损毁", Count: 2, Area: 1},
+		{Name: "B保护区", PrevBHDL: "资源损毁", BHDL: "资源损毁", Count: 3, Area: 4},
+	}}
+	srv := NewNatureService(fake, nil, nil, nil, "")
+
+	groups, err := srv.GetRepeatChanges(model.NatureQueryRequest{})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	// 按保护地合并，重复变化多的排在前面；找不到上期图斑时记为 未知
+	want := []model.RepeatChangeGroup{
+		{Name: "B保护区", Count: 5, Area: 5, Transitions: map[string]int64{"未知→资源损毁": 2, "资源损毁→资源损毁": 3}},
+		{Name: "A保护区", Count: 1, Area: 2, Transitions: map[string]int64{"资源损毁→恢复治理": 1}},
+	}
+	if !reflect.DeepEqual(groups, want) {
+		t.Errorf("groups = %+v, want %+v", groups, want)
+	}
+}
+
+func TestGetRepeatChangesEmpty(t *testing.T) {
+	srv := NewNatureService(&fakeRepeatChangeStore{}, nil, nil, nil, "")
+	groups, err := srv.GetRepeatChanges(model.NatureQueryRequest{})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if groups == nil || len(groups) != 0 {
+		t.Errorf("groups = %#v, want empty slice", groups)
+	}
+}
+
+func TestGetRepeatChangeSpots(t *testing.T) {
+	tests := []struct {
+		name     string
+		page     int
+		pageSize int
+		sort     string
+		wantErr  bool
+	}{
+		{name: "正常分页", page: 1, pageSize: 20, sort: "bhmj"},
+		{name: "page 为 0", page: 0, pageSize: 20, wantErr: true},
+		{name: "page_size 超过上限", page: 1, pageSize: maxRepeatChangePageSize + 1, wantErr: true},
+		{name: "不支持的排序字段", page: 1, pageSize: 20, sort: "password", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeRepeatChangeStore{
+				spots: []model.NatureData{
+					{TBBH: "B2", BHDL: "资源损毁", SQTBBH: "B1"},
+					{TBBH: "C2", BHDL: "恢复治理", SQTBBH: "C1"},
+				},
+				prev: []model.NatureData{{TBBH: "B1", Year: "2022", BHDL: "资源损毁"}},
+			}
+			srv := NewNatureService(fake, nil, nil, nil, "")
+
+			req := model.RepeatChangeSpotsRequest{Name: "B保护区"}
+			req.Page, req.PageSize, req.Sort = tt.page, tt.pageSize, tt.sort
+			result, err := srv.GetRepeatChangeSpots(req)
+			if tt.wantErr {
+				if !errors.Is(err, errcode.InvalidParams) {
+					t.Fatalf("err = %v, want errcode.InvalidParams", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+
+			if fake.name != "B保护区" || fake.req.OrderBy != "BHMJ, TBBH" {
+				t.Errorf("store got name = %q, order by = %q", fake.name, fake.req.OrderBy)
+			}
+			list := result["list"].([]model.RepeatChangeSpot)
+			if list[0].PrevYear != "2022" || list[0].PrevBHDL != "资源损毁" {
+				t.Errorf("spot with previous = %+v", list[0])
+			}
+			// 上期图斑不存在或不在数据范围内时上期字段为空
+			if list[1].PrevYear != "" || list[1].PrevBHDL != "" {
+				t.Errorf("spot without previous = %+v", list[1])
+			}
+		})
+	}
+}
@@ -3,161 +3,242 @@ package service
 import (
 	"ProtectedArea/internal/model"
 	"ProtectedArea/internal/store"
-	"fmt"
+	"ProtectedArea/pkg/errcode"
+	"ProtectedArea/pkg/export"
+	"errors"
+	"math"
 	"os"
-)
+	"path/filepath"
 
-// --- 在文件顶部定义常量 ---
-const (
-	// ConstProtectedCount 这里的值我先随便写的，小鱼你需要改成项目实际的常量值
-	ConstProtectedCount     = 1098        // 保护地个数
-	ConstProtectedTotalArea = 88423024.14 // 保护地总面积
+	"gorm.io/gorm"
 )
 
+// scopeColumns 行政区范围 -> 对应的数据库字段名
+var scopeColumns = map[string]string{
+	"province": "THSHENG",
+	"city":     "THSHI",
+	"county":   "THXIAN",
+}
+
 type NatureService interface {
-	GetTrendAnalysis() (map[string]map[string]int64, error)
+	GetTrendAnalysis(req model.TrendQueryRequest) (map[string]interface{}, error)
 
-	GetYearlyOverview(year string) (map[string]interface{}, error)
-	GetDamageAnalysisByBatch(year string) (map[string]map[string]interface{}, error)
+	GetYearlyOverview(req model.OverviewQueryRequest) (map[string]interface{}, error)
+	GetDamageAnalysisByBatch(year string, userScope model.RegionScope) (map[string]map[string]interface{}, error)
+	// GetBatchStats 分批次统计 (任意变化地类)
+	GetBatchStats(req model.BatchStatsRequest) (map[string]interface{}, error)
 
-	GetAdministrativeStats(year, scope, name string) (interface{}, error)
+	// GetAdministrativeStats compareYear 不为空时每个行政区附带同比数据
+	GetAdministrativeStats(year, scope, name, compareYear string, userScope model.RegionScope) (interface{}, error)
+	// GetRegionTree 省 → 市 → 县 行政区树 (带个数/面积)
+	GetRegionTree(req model.RegionTreeRequest) (map[string]interface{}, error)
+	// GetRegionDict 行政区名称字典 (级联选择用)
+	GetRegionDict(req model.RegionDictRequest) ([]*model.RegionDictNode, error)
 
+	// GetProtectedAreaStats sort 可选 name, count, area
 	GetProtectedAreaStats(req model.NatureQueryRequest) (map[string]interface{}, error)
+	// GetSpotList sort 可选 tbbh, bhmj (area)；fields 不为空时只返回指定字段；带 cursor 时使用游标分页
 	GetSpotList(req model.NatureQueryRequest) (map[string]interface{}, error)
+	// GetRanking 保护地/行政区排行榜 (Top N)
+	GetRanking(req model.RankingRequest) (map[string]interface{}, error)
 	GetTransitionStats(req model.NatureQueryRequest) ([]model.TransitionStat, error)
+	GetTransitionMatrix(req model.TransitionMatrixRequest) (map[string]interface{}, error)
 
+	// GetLargeSpots 带 cursor 时使用游标分页
 	GetLargeSpots(req model.AlertQueryRequest) (map[string]interface{}, error)
 
 	GetImagePath(tbbh string) (string, bool) // 返回路径和是否存在
+
+	// GetSpotDetail 图斑详情，不存在或超出用户数据范围时返回 errcode.SpotNotFound
+	GetSpotDetail(tbbh string, userScope model.RegionScope) (*model.SpotDetail, error)
+	// GetSpotLineage 沿 SQTBBH 追溯图斑在各年份的演变
+	GetSpotLineage(tbbh string, userScope model.RegionScope) (*model.SpotLineage, error)
+	// GetRepeatChanges 按保护地汇总重复变化图斑 (SFCXBH=1)
+	GetRepeatChanges(req model.NatureQueryRequest) ([]model.RepeatChangeGroup, error)
+	// GetRepeatChangeSpots 重复变化图斑明细 (带分页)，附上期图斑的年份和变化地类
+	GetRepeatChangeSpots(req model.RepeatChangeSpotsRequest) (map[string]interface{}, error)
+
+	// GetAttributionDiffStats 核实/原报差异统计 (按省或保护地分组)
+	GetAttributionDiffStats(req model.AttributionDiffRequest) (map[string]interface{}, error)
+	// GetAttributionDiffSpots 核实/原报不一致的图斑明细
+	GetAttributionDiffSpots(req model.AttributionDiffRequest) (map[string]interface{}, error)
+
+	GetSpotsInBBox(req model.BBoxQueryRequest) (map[string]interface{}, error)
+	GetSpotsWithinRadius(req model.RadiusQueryRequest) (map[string]interface{}, error)
+
+	GetSpotClusters(req model.ClusterQueryRequest) (map[string]interface{}, error)
+
+	// StreamSpots 逐条遍历图斑 (用于 GeoJSON 等流式输出)
+	StreamSpots(q model.SpotStreamQuery, fn func(*model.NatureData) error) error
+
+	// 导出 CSV/XLSX: 写入全部匹配的行 (忽略分页)，不负责 Close
+	ExportSpotList(req model.NatureQueryRequest, w export.Writer) error
+	ExportProtectedAreaStats(req model.NatureQueryRequest, w export.Writer) error
+	ExportRegionStats(year, scope, name string, userScope model.RegionScope, w export.Writer) error
+	ExportTransitionStats(req model.NatureQueryRequest, w export.Writer) error
+	ExportLargeSpots(req model.AlertQueryRequest, w export.Writer) error
+	ExportDamageBatchStats(year string, userScope model.RegionScope, w export.Writer) error
 }
 
 type natureService struct {
-	store store.NatureStore
+	store      store.NatureStore
+	paStore    store.ProtectedAreaStore // 保护地名录，用于概况中的保护地个数/总面积
+	imageStore store.ImageStore         // 图片索引，用于判断图斑是否有图片
+	batches    BatchService             // 批次登记表，用于把 PC 归类为批次名称
+	imageRoot  string                   // 图片存放的根目录 (来自配置 image.root)
 }
 
-func NewNatureService(s store.NatureStore) NatureService {
-	return &natureService{store: s}
+func NewNatureService(s store.NatureStore, paStore store.ProtectedAreaStore, imageStore store.ImageStore, batches BatchService, imageRoot string) NatureService {
+	return &natureService{store: s, paStore: paStore, imageStore: imageStore, batches: batches, imageRoot: imageRoot}
 }
 
-// GetTrendAnalysis 处理业务逻辑：数据格式转换
-func (s *natureService) GetTrendAnalysis() (map[string]map[string]int64, error) {
-	// 1. 调用 Store 层获取原始数据
-	rawStats, err := s.store.GetYearlyTrendStats()
-	if err != nil {
+// GetYearlyOverview 1. 业务逻辑：获取年度概况
+// 保护地个数和总面积来自保护地名录 (protected_area)，与图斑统计使用相同的类型/省份筛选
+// 名录只记录到省，市/县级用户无法按其范围统计，因此不返回名录字段
+func (s *natureService) GetYearlyOverview(req model.OverviewQueryRequest) (map[string]interface{}, error) {
+	// 省级及以下用户只能查看本省概况
+	if req.UserScope.Province != "" {
+		if req.Province != "" && req.Province != req.UserScope.Province {
+			return nil, errcode.Forbidden.WithMessage("无权查看其他省份的数据")
+		}
+		req.Province = req.UserScope.Province
+	}
+	if err := validateCompareYear(req.CompareYear); err != nil {
 		return nil, err
 	}
 
-	// 2. 组装业务数据结构
-	// 目标格式: {"资源损毁": {"2020": 352}, "恢复治理": {"2020": 63}}
-	response := map[string]map[string]int64{
-		"资源损毁": make(map[string]int64),
-		"恢复治理": make(map[string]int64),
+	count, area, err := s.store.GetSummaryByYear(req)
+	if err != nil {
+		return nil, err
 	}
 
-	for _, item := range rawStats {
-		if subMap, ok := response[item.BHDL]; ok {
-			subMap[item.Year] = item.Count
+	// 组装返回数据
+	result := map[string]interface{}{
+		"year":        req.Year,
+		"total_count": count, // 当年图斑总数
+		"total_area":  area,  // 当年保护地面积总和
+	}
+	if req.UserScope.City == "" && req.UserScope.County == "" {
+		paCount, paArea, err := s.paStore.GetSummary(req.ProtectedType, req.Province)
+		if err != nil {
+			return nil, err
 		}
+		result["protected_count"] = paCount     // 名录：保护地个数
+		result["protected_total_area"] = paArea // 名录：保护地批复总面积
 	}
 
-	return response, nil
-}
-
-// GetYearlyOverview 1. 业务逻辑：获取年度概况（包含常量）
-func (s *natureService) GetYearlyOverview(year string) (map[string]interface{}, error) {
-	count, area, err := s.store.GetSummaryByYear(year)
-	if err != nil {
-		return nil, err
+	// 同比: 对比年份使用相同的筛选条件
+	if req.CompareYear != "" {
+		prevReq := req
+		prevReq.Year = req.CompareYear
+		prevCount, prevArea, err := s.store.GetSummaryByYear(prevReq)
+		if err != nil {
+			return nil, err
+		}
+		result["compare"] = newStatComparison(req.CompareYear, count, prevCount, area, prevArea)
 	}
-
-	// 组装返回数据
-	return map[string]interface{}{
-		"year":                 year,
-		"total_count":          count,                   // 当年图斑总数
-		"total_area":           area,                    // 当年保护地面积总和
-		"protected_count":      ConstProtectedCount,     // 常量：保护地个数
-		"protected_total_area": ConstProtectedTotalArea, // 常量：保护地总面积
-	}, nil
+	return result, nil
 }
 
 // GetDamageAnalysisByBatch 2. 业务逻辑：分批次统计资源损毁
-func (s *natureService) GetDamageAnalysisByBatch(year string) (map[string]map[string]interface{}, error) {
-	// 1. 先从数据库拿到原始的分组数据
-	// 此时 rawStats 里的 PC 可能是 "202301", "2023-01", "A01" 等各种格式
-	rawStats, err := s.store.GetDamageStatsByBatch(year)
+func (s *natureService) GetDamageAnalysisByBatch(year string, userScope model.RegionScope) (map[string]map[string]interface{}, error) {
+	stats, err := s.damageStatsByBatch(year, userScope)
 	if err != nil {
 		return nil, err
 	}
 
-	// 2. 初始化返回结构
-	// 使用 map 暂存累加结果，防止多个原始 PC 对应同一个“第一批次”
-	// 例如: "202301" 和 "2023-01" 都应该算进 "第一批次"
-	countMap := make(map[string]int64)
-	areaMap := make(map[string]float64)
-
-	// 3. 遍历原始数据，进行清洗和聚合
-	for _, item := range rawStats {
-		// 调用辅助函数，解析出 "第一批次" 等标准名称
-		batchName := s.getBatchNameFromPC(item.PC)
-
-		// 累加数据
-		countMap[batchName] += item.Count
-		areaMap[batchName] += item.Area
-	}
-
-	// 4. 组装最终的 JSON 结构
+	// 组装最终的 JSON 结构
 	// 目标格式: {"资源损毁个数": {...}, "资源损毁面积": {...}}
 	response := map[string]map[string]interface{}{
 		"资源损毁个数": make(map[string]interface{}),
 		"资源损毁面积": make(map[string]interface{}),
 	}
 
-	for name, count := range countMap {
-		response["资源损毁个数"][name] = count
-		response["资源损毁面积"][name] = areaMap[name] // 取出对应的面积
+	for _, item := range stats {
+		response["资源损毁个数"][item.PC] = item.Count
+		response["资源损毁面积"][item.PC] = item.Area
 	}
 
 	return response, nil
 }
 
-// getBatchNameFromPC 辅助函数：根据 PC 字段后两位判断批次
-func (s *natureService) getBatchNameFromPC(pc string) string {
-	// 防止空字符串或长度不足2位的脏数据
-	if len(pc) < 2 {
-		return "未知批次"
-	}
-
-	// 取最后两位
-	suffix := pc[len(pc)-2:]
-
-	switch suffix {
-	case "01":
-		return "第一批次"
-	case "02":
-		return "第二批次"
-	case "03":
-		return "第三批次"
-	case "04":
-		return "第四批次"
-	default:
-		// 如果后两位不是 01-04，归类为其他
-		return "其他批次"
+// damageStatsByBatch 资源损毁分批次统计，返回结果的 PC 字段为批次名称，按批次登记表中的顺序排列
+func (s *natureService) damageStatsByBatch(year string, userScope model.RegionScope) ([]model.BatchStatResult, error) {
+	const changeType = "资源损毁"
+	filter := model.TrendFilter{
+		ChangeTypes: []string{changeType},
+		StartYear:   year,
+		EndYear:     year,
+		GroupCol:    "PC",
+		UserScope:   userScope,
 	}
+	batches, series, err := s.collectBatchStats(filter, year)
+	if err != nil {
+		return nil, err
+	}
+
+	var stats []model.BatchStatResult
+	for _, item := range series {
+		if item.ChangeType != changeType {
+			continue
+		}
+		for i, name := range batches {
+			stats = append(stats, model.BatchStatResult{PC: name, Count: item.Count[i], Area: item.Area[i]})
+		}
+	}
+	return stats, nil
 }
 
-func (s *natureService) GetAdministrativeStats(year, scope, name string) (interface{}, error) {
-	// 1. 定义数据库字段映射
-	// scope -> 对应的数据库字段名
-	colMap := map[string]string{
-		"province": "THSHENG",
-		"city":     "THSHI",
-		"county":   "THXIAN",
+func (s *natureService) GetAdministrativeStats(year, scope, name, compareYear string, userScope model.RegionScope) (interface{}, error) {
+	if err := validateCompareYear(compareYear); err != nil {
+		return nil, err
+	}
+	stats, _, err := s.regionStats(year, scope, name, userScope)
+	if err != nil {
+		return nil, err
+	}
+
+	// 返回一个 Map: {"河北省": {count: 10, area: 100}, "河南省": ...}
+	response := make(map[string]map[string]interface{})
+	for _, item := range stats {
+		response[item.RegionName] = map[string]interface{}{
+			"count": item.Count,
+			"area":  item.Area,
+		}
+	}
+	if compareYear == "" {
+		return response, nil
+	}
+
+	// 同比: 只在对比年份出现的行政区本期按 0 计
+	prevStats, _, err := s.regionStats(compareYear, scope, name, userScope)
+	if err != nil {
+		return nil, err
+	}
+	prevMap := make(map[string]model.RegionStatResult, len(prevStats))
+	for _, item := range prevStats {
+		prevMap[item.RegionName] = item
+		if _, ok := response[item.RegionName]; !ok {
+			response[item.RegionName] = map[string]interface{}{"count": int64(0), "area": 0.0}
+		}
 	}
+	for name, values := range response {
+		prev := prevMap[name]
+		values["compare"] = newStatComparison(compareYear, values["count"].(int64), prev.Count, values["area"].(float64), prev.Area)
+	}
+
+	return response, nil
+}
+
+// regionStats 行政区统计，同时返回分组所用的列名 (THSHENG/THSHI/THXIAN)
+func (s *natureService) regionStats(year, scope, name string, userScope model.RegionScope) ([]model.RegionStatResult, string, error) {
+	// 1. 数据库字段映射见 scopeColumns
+	colMap := scopeColumns
 
 	// 2. 校验 scope 是否合法
 	currentCol, ok := colMap[scope]
 	if !ok {
-		return nil, fmt.Errorf("无效的查询范围(scope): %s", scope)
+		return nil, "", newValidationError("无效的查询范围(scope): %s", scope)
 	}
 
 	var groupCol string  // 最终我们要按哪一列分组
@@ -173,7 +254,7 @@ func (s *natureService) GetAdministrativeStats(year, scope, name string) (interf
 
 		// 边界检查: 县级没有下级
 		if scope == "county" {
-			return nil, fmt.Errorf("县级行政区无法查询下级详情")
+			return nil, "", errcode.BusinessRule.WithMessage("县级行政区无法查询下级详情")
 		}
 
 		filterCol = currentCol // 筛选当前层级 (WHERE THSHENG = '河北')
@@ -187,52 +268,96 @@ func (s *natureService) GetAdministrativeStats(year, scope, name string) (interf
 	}
 
 	// 4. 调用 Store
-	stats, err := s.store.GetRegionStats(year, groupCol, filterCol, name)
+	stats, err := s.store.GetRegionStats(year, groupCol, filterCol, name, userScope)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
-	// 5. 格式化返回数据
-	// 返回一个 Map: {"河北省": {count: 10, area: 100}, "河南省": ...}
-	response := make(map[string]map[string]interface{})
-	for _, item := range stats {
-		// 防止空名数据
-		regionName := item.RegionName
-		if regionName == "" {
-			regionName = "未知区域"
-		}
-
-		response[regionName] = map[string]interface{}{
-			"count": item.Count,
-			"area":  item.Area,
+	// 5. 防止空名数据
+	for i := range stats {
+		if stats[i].RegionName == "" {
+			stats[i].RegionName = "未知区域"
 		}
 	}
-
-	return response, nil
+	return stats, groupCol, nil
 }
 
 // GetProtectedAreaStats 接口1 Service
 func (s *natureService) GetProtectedAreaStats(req model.NatureQueryRequest) (map[string]interface{}, error) {
+	if err := validateCompareYear(req.CompareYear); err != nil {
+		return nil, err
+	}
+	orderBy, err := buildOrderBy(req.Sort, req.Order, protectedAreaSortColumns, "name")
+	if err != nil {
+		return nil, err
+	}
+	req.OrderBy = orderBy
+
 	list, total, err := s.store.GetProtectedAreaStats(req)
 	if err != nil {
 		return nil, err
 	}
+
+	// 同比: 查询对比年份的全部保护地 (不分页)，再按名称对应到当前页
+	if req.CompareYear != "" {
+		prevReq := req
+		prevReq.Year = req.CompareYear
+		prevReq.PageSize = 0
+		prevList, _, err := s.store.GetProtectedAreaStats(prevReq)
+		if err != nil {
+			return nil, err
+		}
+		prevMap := make(map[string]model.ProtectedAreaStat, len(prevList))
+		for _, item := range prevList {
+			prevMap[item.Name] = item
+		}
+		for i := range list {
+			prev := prevMap[list[i].Name]
+			list[i].Compare = newStatComparison(req.CompareYear, list[i].Count, prev.Count, list[i].Area, prev.Area)
+		}
+	}
 	// 使用辅助函数返回
-	return s.buildPagedResponse(list, total, req.Page, req.PageSize), nil
+	return buildPagedResponse(list, total, req.Page, req.PageSize), nil
 }
 
 // GetSpotList 接口2 Service
 func (s *natureService) GetSpotList(req model.NatureQueryRequest) (map[string]interface{}, error) {
+	if req.Cursor != nil {
+		return s.getSpotListByCursor(req)
+	}
+
+	orderBy, err := buildOrderBy(req.Sort, req.Order, spotSortColumns, "TBBH")
+	if err != nil {
+		return nil, err
+	}
+	req.OrderBy = orderBy
+
+	// 指定了 fields 时只查询并返回这些字段
+	if req.Fields != "" {
+		columns, indexes, err := parseSpotFields(req.Fields)
+		if err != nil {
+			return nil, err
+		}
+		list, total, err := s.store.GetSpotListColumns(req, columns)
+		if err != nil {
+			return nil, err
+		}
+		return buildPagedResponse(projectSpots(list, indexes), total, req.Page, req.PageSize), nil
+	}
+
 	list, total, err := s.store.GetSpotList(req)
 	if err != nil {
 		return nil, err
 	}
 	// 使用辅助函数返回
-	return s.buildPagedResponse(list, total, req.Page, req.PageSize), nil
+	return buildPagedResponse(list, total, req.Page, req.PageSize), nil
 }
 
 // GetTransitionStats 接口3 Service: 计算占比
 func (s *natureService) GetTransitionStats(req model.NatureQueryRequest) ([]model.TransitionStat, error) {
+	if err := validateCompareYear(req.CompareYear); err != nil {
+		return nil, err
+	}
 	stats, err := s.store.GetTransitionStats(req)
 	if err != nil {
 		return nil, err
@@ -256,11 +381,38 @@ func (s *natureService) GetTransitionStats(req model.NatureQueryRequest) ([]mode
 		}
 	}
 
+	// 3. 同比: 只在对比年份出现的后地类本期按 0 计
+	if req.CompareYear != "" {
+		prevReq := req
+		prevReq.Year = req.CompareYear
+		prevStats, err := s.store.GetTransitionStats(prevReq)
+		if err != nil {
+			return nil, err
+		}
+		prevMap := make(map[string]model.TransitionStat, len(prevStats))
+		for _, item := range prevStats {
+			prevMap[item.HLX] = item
+		}
+		seen := make(map[string]bool, len(stats))
+		for _, item := range stats {
+			seen[item.HLX] = true
+		}
+		for _, item := range prevStats {
+			if !seen[item.HLX] {
+				stats = append(stats, model.TransitionStat{HLX: item.HLX})
+			}
+		}
+		for i := range stats {
+			prev := prevMap[stats[i].HLX]
+			stats[i].Compare = newStatComparison(req.CompareYear, stats[i].Count, prev.Count, stats[i].Area, prev.Area)
+		}
+	}
+
 	return stats, nil
 }
 
 // buildPagedResponse 构建带有详细分页信息的返回结构
-func (s *natureService) buildPagedResponse(list interface{}, total int64, page int, pageSize int) map[string]interface{} {
+func buildPagedResponse(list interface{}, total int64, page int, pageSize int) map[string]interface{} {
 	// 计算总页数：向上取整
 	// 算法原理: (total + pageSize - 1) / pageSize
 	totalPages := 0
@@ -280,25 +432,46 @@ func (s *natureService) buildPagedResponse(list interface{}, total int64, page i
 }
 
 func (s *natureService) GetLargeSpots(req model.AlertQueryRequest) (map[string]interface{}, error) {
+	if req.Cursor != nil {
+		return s.getLargeSpotsByCursor(req)
+	}
+
+	orderBy, err := largeSpotsOrderBy(req)
+	if err != nil {
+		return nil, err
+	}
+	req.OrderBy = orderBy
+
 	list, total, err := s.store.GetLargeSpots(req)
 	if err != nil {
 		return nil, err
 	}
 
 	// 复用之前的分页组装逻辑
-	return s.buildPagedResponse(list, total, req.Page, req.PageSize), nil
+	return buildPagedResponse(list, total, req.Page, req.PageSize), nil
+}
+
+// largeSpotsOrderBy 大图斑预警的排序: 可选 bhmj, tbbh，未指定方向时降序
+func largeSpotsOrderBy(req model.AlertQueryRequest) (string, error) {
+	order := req.Order
+	if order == "" {
+		order = "desc"
+	}
+	return buildOrderBy(req.Sort, order, largeSpotsSortColumns, "TBBH")
 }
 
 // GetImagePath 查找图片文件路径
 func (s *natureService) GetImagePath(tbbh string) (string, bool) {
-	// 图片存放的根目录
-	baseDir := "./image/"
+	return findLegacyImage(s.imageRoot, tbbh)
+}
 
+// findLegacyImage 按旧的单图命名 (root/TBBH.jpg) 查找图片
+func findLegacyImage(root, tbbh string) (string, bool) {
 	// 支持的后缀名列表，你可以根据实际情况添加 .jpeg 等
 	extensions := []string{".jpg", ".png", ".jpeg"}
 
 	for _, ext := range extensions {
-		filePath := baseDir + tbbh + ext
+		filePath := filepath.Join(root, tbbh+ext)
 		// os.Stat 用于获取文件信息，如果 err == nil 说明文件存在
 		if _, err := os.Stat(filePath); err == nil {
 			return filePath, true
@@ -307,3 +480,139 @@ func (s *natureService) GetImagePath(tbbh string) (string, bool) {
 
 	return "", false
 }
+
+// GetSpotDetail 图斑详情: 完整记录 + 批次名称 + 是否有图片 (图片索引中有记录，或存在旧命名的 TBBH.jpg)
+// 保护地类型中文名由 handler 根据 ProtectedTypeMap 填充
+func (s *natureService) GetSpotDetail(tbbh string, userScope model.RegionScope) (*model.SpotDetail, error) {
+	spot, err := s.store.GetSpot(tbbh, userScope)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errcode.SpotNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	images, err := s.imageStore.ListByTBBH(spot.TBBH)
+	if err != nil {
+		return nil, err
+	}
+	hasImage := len(images) > 0
+	if !hasImage {
+		// 尚未扫描进索引的旧文件
+		_, hasImage = s.GetImagePath(spot.TBBH)
+	}
+	return &model.SpotDetail{
+		NatureData: *spot,
+		BatchName:  s.batchName(spot.PC, spot.Year),
+		HasImage:   hasImage,
+	}, nil
+}
+
+// batchName 根据批次登记表返回 PC 对应的批次名称
+func (s *natureService) batchName(pc, year string) string {
+	name, _ := s.batches.Resolve(pc, year)
+	return name
+}
+
+// GetSpotsInBBox 范围查询 Service
+func (s *natureService) GetSpotsInBBox(req model.BBoxQueryRequest) (map[string]interface{}, error) {
+	bbox, err := model.ParseBBox(req.BBox)
+	if err != nil {
+		return nil, newValidationError("%s", err.Error())
+	}
+	orderBy, err := buildOrderBy(req.Sort, req.Order, spotSortColumns, "TBBH")
+	if err != nil {
+		return nil, err
+	}
+	req.OrderBy = orderBy
+
+	list, total, err := s.store.GetSpotsInBBox(req.NatureQueryRequest, *bbox)
+	if err != nil {
+		return nil, err
+	}
+	return buildPagedResponse(list, total, req.Page, req.PageSize), nil
+}
+
+// maxRadiusMeters 半径查询允许的最大半径 (500 公里)
+const maxRadiusMeters = 500000
+
+// GetSpotsWithinRadius 半径查询 Service
+func (s *natureService) GetSpotsWithinRadius(req model.RadiusQueryRequest) (map[string]interface{}, error) {
+	x, y := *req.X, *req.Y
+	if x < -180 || x > 180 || y < -90 || y > 90 {
+		return nil, newValidationError("圆心坐标超出经纬度范围: x=%v, y=%v", x, y)
+	}
+	if req.Radius <= 0 || req.Radius > maxRadiusMeters {
+		return nil, newValidationError("半径(radius)必须在 0-%d 米之间", maxRadiusMeters)
+	}
+	orderBy, err := buildOrderBy(req.Sort, req.Order, radiusSortColumns, "TBBH")
+	if err != nil {
+		return nil, err
+	}
+	req.OrderBy = orderBy
+
+	list, total, err := s.store.GetSpotsWithinRadius(req.NatureQueryRequest, x, y, req.Radius)
+	if err != nil {
+		return nil, err
+	}
+	return buildPagedResponse(list, total, req.Page, req.PageSize), nil
+}
+
+const (
+	maxClusterZoom = 22 // 允许的最大缩放级别
+	// clusterCellsPerTile 每个 256px 瓦片横向划分的网格数，即每个网格约 64px
+	clusterCellsPerTile = 4
+	// maxClusterCells 一次聚合最多划分的网格数，超过时加大网格边长
+	maxClusterCells = 10000
+)
+
+// GetSpotClusters 网格聚合 Service: 根据缩放级别计算网格大小
+// 缩放级别 z 下一个瓦片覆盖 360/2^z 度经度，每个瓦片再分成 clusterCellsPerTile 格
+// bbox 相对缩放级别过大 (网格数超过 maxClusterCells) 时网格边长逐级加倍，并返回 coarsened=true
+func (s *natureService) GetSpotClusters(req model.ClusterQueryRequest) (map[string]interface{}, error) {
+	bbox, err := model.ParseBBox(req.BBox)
+	if err != nil {
+		return nil, newValidationError("%s", err.Error())
+	}
+	if req.Zoom < 0 || req.Zoom > maxClusterZoom {
+		return nil, newValidationError("缩放级别(zoom)必须在 0-%d 之间", maxClusterZoom)
+	}
+
+	cellSize := 360 / math.Pow(2, float64(req.Zoom)) / clusterCellsPerTile
+	coarsened := false
+	for clusterCellCount(*bbox, cellSize) > maxClusterCells {
+		cellSize *= 2
+		coarsened = true
+	}
+
+	clusters, err := s.store.GetSpotClusters(req.NatureQueryRequest, *bbox, cellSize)
+	if err != nil {
+		return nil, err
+	}
+
+	var totalCount int64
+	for _, c := range clusters {
+		totalCount += c.Count
+	}
+
+	return map[string]interface{}{
+		"zoom":        req.Zoom,
+		"cell_size":   cellSize,  // 网格边长 (度)
+		"coarsened":   coarsened, // 是否因网格数超过上限而加大了网格边长
+		"bbox":        bbox,
+		"total_count": totalCount,
+		"clusters":    clusters,
+	}, nil
+}
+
+// clusterCellCount bbox 按 cellSize 划分后的网格数
+func clusterCellCount(bbox model.BBox, cellSize float64) float64 {
+	cols := math.Max(math.Ceil((bbox.MaxX-bbox.MinX)/cellSize), 1)
+	rows := math.Max(math.Ceil((bbox.MaxY-bbox.MinY)/cellSize), 1)
+	return cols * rows
+}
+
+// StreamSpots 逐条遍历图斑，直接透传给 Store
+func (s *natureService) StreamSpots(q model.SpotStreamQuery, fn func(*model.NatureData) error) error {
+	return s.store.StreamSpots(q, fn)
+}
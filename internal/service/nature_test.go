@@ -0,0 +1,96 @@
+package service
+
+import (
+	"ProtectedArea/internal/model"
+	"ProtectedArea/internal/store"
+	"ProtectedArea/pkg/errcode"
+	"errors"
+	"testing"
+)
+
+// fakeClusterStore 记录聚合时使用的网格边长
+type fakeClusterStore struct {
+	store.NatureStore
+	cellSize float64
+}
+
+func (f *fakeClusterStore) GetSpotClusters(req model.NatureQueryRequest, bbox model.BBox, cellSize float64) ([]model.SpotCluster, error) {
+	f.cellSize = cellSize
+	return []model.SpotCluster{{Count: 3}, {Count: 4}}, nil
+}
+
+func TestClusterCellCount(t *testing.T) {
+	tests := []struct {
+		name     string
+		bbox     model.BBox
+		cellSize float64
+		want     float64
+	}{
+		{name: "整除", bbox: model.BBox{MinX: 0, MinY: 0, MaxX: 10, MaxY: 5}, cellSize: 1, want: 50},
+		{name: "不足一格向上取整", bbox: model.BBox{MinX: 0, MinY: 0, MaxX: 10.5, MaxY: 5.1}, cellSize: 1, want: 66},
+		{name: "空范围至少一格", bbox: model.BBox{MinX: 116, MinY: 39, MaxX: 116, MaxY: 39}, cellSize: 0.5, want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := clusterCellCount(tt.bbox, tt.cellSize); got != tt.want {
+				t.Errorf("clusterCellCount = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetSpotClusters(t *testing.T) {
+	tests := []struct {
+		name          string
+		bbox          string
+		zoom          int
+		wantCellSize  float64
+		wantCoarsened bool
+		wantErr       bool
+	}{
+		// zoom=0: 360/1/4 = 90 度，全球 4x2 格
+		{name: "网格数在上限内", bbox: "-180,-90,180,90", zoom: 0, wantCellSize: 90},
+		// zoom=22 的网格远小于 1 度，全球范围需要逐级加倍直到不超过上限
+		{name: "网格数超限时加大网格", bbox: "-180,-90,180,90", zoom: 22, wantCoarsened: true},
+		{name: "缩放级别超出范围", bbox: "0,0,1,1", zoom: 23, wantErr: true},
+		{name: "非法 bbox", bbox: "0,0,1", zoom: 5, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeClusterStore{}
+			srv := NewNatureService(fake, nil, nil, nil, "")
+			result, err := srv.GetSpotClusters(model.ClusterQueryRequest{BBox: tt.bbox, Zoom: tt.zoom})
+			if tt.wantErr {
+				if !errors.Is(err, errcode.InvalidParams) {
+					t.Fatalf("err = %v, want errcode.InvalidParams", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+
+			if result["coarsened"] != tt.wantCoarsened {
+				t.Errorf("coarsened = %v, want %v", result["coarsened"], tt.wantCoarsened)
+			}
+			if result["cell_size"] != fake.cellSize {
+				t.Errorf("cell_size = %v, store got %v", result["cell_size"], fake.cellSize)
+			}
+			if tt.wantCellSize != 0 && fake.cellSize != tt.wantCellSize {
+				t.Errorf("cell size = %v, want %v", fake.cellSize, tt.wantCellSize)
+			}
+			bbox, _ := model.ParseBBox(tt.bbox)
+			if n := clusterCellCount(*bbox, fake.cellSize); n > maxClusterCells {
+				t.Errorf("cell count = %v, want <= %d", n, maxClusterCells)
+			}
+			// 只加倍到刚好不超过上限
+			if tt.wantCoarsened && clusterCellCount(*bbox, fake.cellSize/2) <= maxClusterCells {
+				t.Errorf("cell size %v is coarser than needed", fake.cellSize)
+			}
+			if result["total_count"] != int64(7) {
+				t.Errorf("total_count = %v, want 7", result["total_count"])
+			}
+		})
+	}
+}
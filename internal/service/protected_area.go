@@ -0,0 +1,115 @@
+package service
+
+import (
+	"ProtectedArea/internal/model"
+	"ProtectedArea/internal/store"
+	"ProtectedArea/pkg/errcode"
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ProtectedAreaService interface {
+	List(req model.ProtectedAreaQueryRequest) (map[string]interface{}, error)
+	Get(id uint) (*model.ProtectedArea, error)
+	Create(input model.ProtectedAreaInput) (*model.ProtectedArea, error)
+	Update(id uint, input model.ProtectedAreaInput) (*model.ProtectedArea, error)
+	Delete(id uint) error
+}
+
+type protectedAreaService struct {
+	store store.ProtectedAreaStore
+}
+
+func NewProtectedAreaService(s store.ProtectedAreaStore) ProtectedAreaService {
+	return &protectedAreaService{store: s}
+}
+
+// List 分页查询保护地名录
+func (s *protectedAreaService) List(req model.ProtectedAreaQueryRequest) (map[string]interface{}, error) {
+	list, total, err := s.store.List(req)
+	if err != nil {
+		return nil, err
+	}
+	return buildPagedResponse(list, total, req.Page, req.PageSize), nil
+}
+
+func (s *protectedAreaService) Get(id uint) (*model.ProtectedArea, error) {
+	pa, err := s.store.GetByID(id)
+	if err != nil {
+		return nil, translateProtectedAreaError(err)
+	}
+	return pa, nil
+}
+
+func (s *protectedAreaService) Create(input model.ProtectedAreaInput) (*model.ProtectedArea, error) {
+	if err := validateProtectedAreaInput(input); err != nil {
+		return nil, err
+	}
+
+	pa := &model.ProtectedArea{}
+	applyProtectedAreaInput(pa, input)
+	if err := s.store.Create(pa); err != nil {
+		return nil, translateProtectedAreaError(err)
+	}
+	return pa, nil
+}
+
+func (s *protectedAreaService) Update(id uint, input model.ProtectedAreaInput) (*model.ProtectedArea, error) {
+	if err := validateProtectedAreaInput(input); err != nil {
+		return nil, err
+	}
+
+	// 先查出原记录，保证 ID 和创建时间不被覆盖
+	pa, err := s.store.GetByID(id)
+	if err != nil {
+		return nil, translateProtectedAreaError(err)
+	}
+	applyProtectedAreaInput(pa, input)
+	if err := s.store.Update(pa); err != nil {
+		return nil, translateProtectedAreaError(err)
+	}
+	return pa, nil
+}
+
+func (s *protectedAreaService) Delete(id uint) error {
+	return translateProtectedAreaError(s.store.Delete(id))
+}
+
+// validateProtectedAreaInput 业务校验 (binding 标签无法覆盖的部分)
+func validateProtectedAreaInput(input model.ProtectedAreaInput) error {
+	if strings.TrimSpace(input.Name) == "" {
+		return newValidationError("保护地名称(name)不能为空")
+	}
+	if input.EstablishedDate != "" {
+		if _, err := time.Parse(time.DateOnly, input.EstablishedDate); err != nil {
+			return newValidationError("设立日期(established_date)格式应为 YYYY-MM-DD: %s", input.EstablishedDate)
+		}
+	}
+	return nil
+}
+
+// applyProtectedAreaInput 把请求体的字段复制到实体上
+func applyProtectedAreaInput(pa *model.ProtectedArea, input model.ProtectedAreaInput) {
+	pa.Name = strings.TrimSpace(input.Name)
+	pa.TypeCode = input.TypeCode
+	pa.Level = input.Level
+	pa.Province = input.Province
+	pa.OfficialArea = input.OfficialArea
+	pa.EstablishedDate = input.EstablishedDate
+	pa.ManagingAgency = input.ManagingAgency
+}
+
+// translateProtectedAreaError 把数据库错误转换为业务错误
+func translateProtectedAreaError(err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return errcode.ProtectedAreaNotFound
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return errcode.ProtectedAreaExists
+	default:
+		return err
+	}
+}
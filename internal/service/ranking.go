@@ -0,0 +1,82 @@
+package service
+
+import (
+	"ProtectedArea/internal/model"
+)
+
+// maxRankingTop 排行榜最多返回的条数
+const maxRankingTop = 100
+
+// rankingColumns 排名对象 -> 分组字段
+var rankingColumns = map[string]string{
+	"protected_area": "THBHDMC",
+	"province":       "THSHENG",
+	"city":           "THSHI",
+	"county":         "THXIAN",
+}
+
+// rankingOrders 排名指标 -> ORDER BY (并列时依次比较另一指标和名称)
+var rankingOrders = map[string]string{
+	"count": "count DESC, area DESC, name",
+	"area":  "area DESC, count DESC, name",
+}
+
+// GetRanking 保护地/行政区排行榜 (按图斑个数或面积取前 N 名)
+// 返回格式: {"year": "2024", "by": "province", "metric": "count", "change_type": "资源损毁", "items": [{"rank": 1, ...}]}
+func (s *natureService) GetRanking(req model.RankingRequest) (map[string]interface{}, error) {
+	// 1. 校验参数并补充默认值
+	if req.By == "" {
+		req.By = "protected_area"
+	}
+	groupCol, ok := rankingColumns[req.By]
+	if !ok {
+		return nil, newValidationError("不支持的排名对象(by): %s，可选值: protected_area, province, city, county", req.By)
+	}
+	if req.Metric == "" {
+		req.Metric = "count"
+	}
+	orderBy, ok := rankingOrders[req.Metric]
+	if !ok {
+		return nil, newValidationError("不支持的排名指标(metric): %s，可选值: count, area", req.Metric)
+	}
+	if req.Top < 1 || req.Top > maxRankingTop {
+		return nil, newValidationError("top 必须在 1-%d 之间", maxRankingTop)
+	}
+
+	// 2. 变化地类默认只统计资源损毁，all 表示不限
+	changeType := req.ChangeType
+	switch changeType {
+	case "":
+		changeType = "资源损毁"
+		req.ChangeType = changeType
+	case "all":
+		changeType = ""
+	}
+
+	// 3. 查询并填充名次
+	items, err := s.store.GetRanking(model.NatureQueryRequest{
+		Year:          req.Year,
+		Scope:         req.Scope,
+		RegionName:    req.RegionName,
+		ProtectedType: req.ProtectedType,
+		ChangeType:    changeType,
+		UserScope:     req.UserScope,
+	}, groupCol, orderBy, req.Top)
+	if err != nil {
+		return nil, err
+	}
+	if items == nil {
+		items = []model.RankingItem{}
+	}
+	for i := range items {
+		items[i].Rank = i + 1
+	}
+
+	return map[string]interface{}{
+		"year":        req.Year,
+		"by":          req.By,
+		"metric":      req.Metric,
+		"change_type": req.ChangeType,
+		"items":       items,
+	}, nil
+}
@@ -0,0 +1,120 @@
+package service
+
+import "ProtectedArea/internal/model"
+
+// regionLevels 行政区层级，下标 + 1 即 depth
+var regionLevels = []struct {
+	Name   string // province, city, county
+	Column string
+}{
+	{"province", "THSHENG"},
+	{"city", "THSHI"},
+	{"county", "THXIAN"},
+}
+
+// regionGroupColumns 根据层级深度返回分组列，depth 超出 1-3 时返回校验错误
+func regionGroupColumns(depth int) ([]string, error) {
+	if depth < 1 || depth > len(regionLevels) {
+		return nil, newValidationError("层级(depth)必须在 1-%d 之间", len(regionLevels))
+	}
+	cols := make([]string, depth)
+	for i := range cols {
+		cols[i] = regionLevels[i].Column
+	}
+	return cols, nil
+}
+
+// regionPath 一行结果的 [省, 市, 县] 名称 (截取到 depth)，空名称统一为 "未知区域"
+func regionPath(row model.RegionRow, depth int) []string {
+	path := []string{row.Province, row.City, row.County}[:depth]
+	for i := range path {
+		if path[i] == "" {
+			path[i] = "未知区域"
+		}
+	}
+	return path
+}
+
+// GetRegionTree 省 → 市 → 县 行政区树，每个节点带个数和面积合计
+// 只查询一次最低层级的分组结果，上级节点的合计在内存中累加
+func (s *natureService) GetRegionTree(req model.RegionTreeRequest) (map[string]interface{}, error) {
+	// 1. 校验层级
+	cols, err := regionGroupColumns(req.Depth)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. 查询最低层级的分组统计
+	rows, err := s.store.GetRegionTreeStats(req, cols)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. 逐行挂到树上 (rows 已按名称排序，子节点顺序与之一致)
+	root := &model.RegionNode{}
+	index := make(map[*model.RegionNode]map[string]*model.RegionNode)
+	for _, row := range rows {
+		root.Count += row.Count
+		root.Area += row.Area
+
+		parent := root
+		for level, name := range regionPath(row, req.Depth) {
+			if index[parent] == nil {
+				index[parent] = make(map[string]*model.RegionNode)
+			}
+			node, ok := index[parent][name]
+			if !ok {
+				node = &model.RegionNode{Name: name, Level: regionLevels[level].Name}
+				index[parent][name] = node
+				parent.Children = append(parent.Children, node)
+			}
+			node.Count += row.Count
+			node.Area += row.Area
+			parent = node
+		}
+	}
+
+	children := root.Children
+	if children == nil {
+		children = []*model.RegionNode{}
+	}
+	return map[string]interface{}{
+		"year":        req.Year,
+		"depth":       req.Depth,
+		"total_count": root.Count,
+		"total_area":  root.Area,
+		"tree":        children,
+	}, nil
+}
+
+// GetRegionDict 行政区字典: 数据中出现过的省 → 市 → 县 名称，用于级联选择
+func (s *natureService) GetRegionDict(req model.RegionDictRequest) ([]*model.RegionDictNode, error) {
+	cols, err := regionGroupColumns(req.Depth)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.store.GetRegionNames(req.Year, cols, req.UserScope)
+	if err != nil {
+		return nil, err
+	}
+
+	root := &model.RegionDictNode{Children: []*model.RegionDictNode{}}
+	index := make(map[*model.RegionDictNode]map[string]*model.RegionDictNode)
+	for _, row := range rows {
+		parent := root
+		for _, name := range regionPath(row, req.Depth) {
+			if index[parent] == nil {
+				index[parent] = make(map[string]*model.RegionDictNode)
+			}
+			node, ok := index[parent][name]
+			if !ok {
+				node = &model.RegionDictNode{Name: name}
+				index[parent][name] = node
+				parent.Children = append(parent.Children, node)
+			}
+			parent = node
+		}
+	}
+	return root.Children, nil
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"ProtectedArea/internal/model"
+	"strings"
+)
+
+// 各列表接口允许的排序字段: sort 参数 -> ORDER BY 中使用的列名或别名
+var (
+	// protectedAreaSortColumns 保护地统计 (聚合结果的别名)
+	protectedAreaSortColumns = map[string]string{
+		"name":  "name",
+		"count": "count",
+		"area":  "area",
+	}
+	// spotSortColumns 图斑明细类接口 (area 与 bhmj 等价)
+	spotSortColumns = map[string]string{
+		"tbbh": "TBBH",
+		"bhmj": "BHMJ",
+		"area": "BHMJ",
+	}
+	// radiusSortColumns 半径查询额外支持按距离排序
+	radiusSortColumns = map[string]string{
+		"tbbh":     "TBBH",
+		"bhmj":     "BHMJ",
+		"area":     "BHMJ",
+		"distance": "distance",
+	}
+	// largeSpotsSortColumns 大图斑预警
+	largeSpotsSortColumns = map[string]string{
+		"bhmj": "BHMJ",
+		"tbbh": "TBBH",
+	}
+)
+
+// buildOrderBy 根据白名单把 sort/order 转换为 ORDER BY 子句
+// sort 为空时返回空串 (使用 Store 的默认排序)；tiebreak 用于保证分页结果稳定
+func buildOrderBy(sort, order string, columns map[string]string, tiebreak string) (string, error) {
+	order = strings.ToLower(strings.TrimSpace(order))
+	if order != "" && order != "asc" && order != "desc" {
+		return "", newValidationError("不支持的排序方向(order): %s，可选值: asc, desc", order)
+	}
+
+	sort = strings.ToLower(strings.TrimSpace(sort))
+	if sort == "" {
+		return "", nil
+	}
+	column, ok := columns[sort]
+	if !ok {
+		return "", newValidationError("不支持的排序字段(sort): %s", sort)
+	}
+
+	clause := column
+	if order == "desc" {
+		clause += " DESC"
+	}
+	if tiebreak != "" && tiebreak != column {
+		clause += ", " + tiebreak
+	}
+	return clause, nil
+}
+
+// parseSpotFields 解析 fields 参数 (逗号分隔的 JSON 字段名)，返回对应的数据库列名和 NatureFields 下标
+func parseSpotFields(fields string) ([]string, []int, error) {
+	var columns []string
+	var indexes []int
+	seen := make(map[int]bool)
+
+	for _, name := range strings.Split(fields, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		idx := -1
+		for i, f := range model.NatureFields {
+			if f.JSON == name {
+				idx = i
+				break
+			}
+		}
+		if idx < 0 {
+			return nil, nil, newValidationError("不支持的字段(fields): %s", name)
+		}
+		if seen[idx] {
+			continue
+		}
+		seen[idx] = true
+		columns = append(columns, model.NatureFields[idx].Column)
+		indexes = append(indexes, idx)
+	}
+
+	if len(columns) == 0 {
+		return nil, nil, newValidationError("fields 不能为空")
+	}
+	return columns, indexes, nil
+}
+
+// projectSpots 只保留 indexes 对应的字段，键为 JSON 字段名
+func projectSpots(list []model.NatureData, indexes []int) []map[string]interface{} {
+	items := make([]map[string]interface{}, 0, len(list))
+	for i := range list {
+		values := list[i].Values()
+		item := make(map[string]interface{}, len(indexes))
+		for _, idx := range indexes {
+			item[model.NatureFields[idx].JSON] = values[idx]
+		}
+		items = append(items, item)
+	}
+	return items
+}
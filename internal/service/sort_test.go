@@ -0,0 +1,165 @@
+package service
+
+import (
+	"ProtectedArea/internal/model"
+	"ProtectedArea/internal/store"
+	"ProtectedArea/pkg/errcode"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestBuildOrderBy(t *testing.T) {
+	tests := []struct {
+		name     string
+		sort     string
+		order    string
+		columns  map[string]string
+		tiebreak string
+		want     string
+		wantErr  bool
+	}{
+		{name: "未指定排序", sort: "", order: "desc", columns: spotSortColumns, tiebreak: "TBBH", want: ""},
+		{name: "升序并追加 tiebreak", sort: "bhmj", columns: spotSortColumns, tiebreak: "TBBH", want: "BHMJ, TBBH"},
+		{name: "降序", sort: "area", order: "desc", columns: spotSortColumns, tiebreak: "TBBH", want: "BHMJ DESC, TBBH"},
+		{name: "大小写和空白", sort: " BHMJ ", order: " DESC ", columns: spotSortColumns, tiebreak: "TBBH", want: "BHMJ DESC, TBBH"},
+		{name: "排序列与 tiebreak 相同", sort: "tbbh", order: "desc", columns: spotSortColumns, tiebreak: "TBBH", want: "TBBH DESC"},
+		{name: "不需要 tiebreak", sort: "distance", columns: radiusSortColumns, want: "distance"},
+		{name: "不在白名单中的列", sort: "THSHENG; DROP TABLE nature_data", columns: spotSortColumns, tiebreak: "TBBH", wantErr: true},
+		{name: "其它接口的列", sort: "distance", columns: spotSortColumns, tiebreak: "TBBH", wantErr: true},
+		{name: "无效的排序方向", sort: "bhmj", order: "up", columns: spotSortColumns, tiebreak: "TBBH", wantErr: true},
+		{name: "未指定排序也校验方向", sort: "", order: "up", columns: spotSortColumns, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := buildOrderBy(tt.sort, tt.order, tt.columns, tt.tiebreak)
+			if tt.wantErr {
+				if !errors.Is(err, errcode.InvalidParams) {
+					t.Fatalf("err = %v, want errcode.InvalidParams", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("buildOrderBy = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSpotFields(t *testing.T) {
+	columns, indexes, err := parseSpotFields(" TBBH, bhmj,,tbbh,thsheng")
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if want := []string{"TBBH", "BHMJ", "THSHENG"}; !reflect.DeepEqual(columns, want) {
+		t.Errorf("columns = %q, want %q", columns, want)
+	}
+	for i, idx := range indexes {
+		if model.NatureFields[idx].Column != columns[i] {
+			t.Errorf("indexes[%d] = %d (%s), want %s", i, idx, model.NatureFields[idx].Column, columns[i])
+		}
+	}
+
+	for _, fields := range []string{"", " , ", "tbbh,password"} {
+		if _, _, err := parseSpotFields(fields); !errors.Is(err, errcode.InvalidParams) {
+			t.Errorf("parseSpotFields(%q) err = %v, want errcode.InvalidParams", fields, err)
+		}
+	}
+}
+
+// fakeExportStore 记录流式查询条件，并返回固定的图斑
+type fakeExportStore struct {
+	store.NatureStore
+	query model.SpotStreamQuery
+	spots []model.NatureData
+}
+
+func (f *fakeExportStore) StreamSpots(q model.SpotStreamQuery, fn func(*model.NatureData) error) error {
+	f.query = q
+	for i := range f.spots {
+		if err := fn(&f.spots[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rowsWriter 把写入的行保存在内存中
+type rowsWriter struct {
+	rows [][]interface{}
+}
+
+func (w *rowsWriter) WriteRow(cells ...interface{}) error {
+	w.rows = append(w.rows, cells)
+	return nil
+}
+
+func (w *rowsWriter) Close() error { return nil }
+
+func TestExportSpotList(t *testing.T) {
+	spots := []model.NatureData{{TBBH: "A1", BHMJ: 2.5, THSHENG: "河北省"}}
+
+	tests := []struct {
+		name        string
+		sort, order string
+		fields      string
+		wantOrderBy string
+		wantHeader  []interface{}
+		wantRow     []interface{}
+		wantErr     bool
+	}{
+		{
+			name:       "默认排序全部字段",
+			wantHeader: model.NatureFieldLabels(),
+			wantRow:    spots[0].Values(),
+		},
+		{
+			name:        "与列表接口相同的排序和字段",
+			sort:        "bhmj",
+			order:       "desc",
+			fields:      "tbbh,bhmj",
+			wantOrderBy: "BHMJ DESC, TBBH",
+			wantHeader:  []interface{}{model.NatureFieldLabel("TBBH"), model.NatureFieldLabel("BHMJ")},
+			wantRow:     []interface{}{"A1", 2.5},
+		},
+		{name: "无效的排序字段", sort: "password", wantErr: true},
+		{name: "无效的字段", fields: "password", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeExportStore{spots: spots}
+			srv := NewNatureService(fake, nil, nil, nil, "")
+			w := &rowsWriter{}
+
+			err := srv.ExportSpotList(model.NatureQueryRequest{Sort: tt.sort, Order: tt.order, Fields: tt.fields}, w)
+			if tt.wantErr {
+				if !errors.Is(err, errcode.InvalidParams) {
+					t.Fatalf("err = %v, want errcode.InvalidParams", err)
+				}
+				if len(w.rows) != 0 {
+					t.Errorf("rows written before validation failed: %v", w.rows)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+
+			if fake.query.OrderBy != tt.wantOrderBy {
+				t.Errorf("order by = %q, want %q", fake.query.OrderBy, tt.wantOrderBy)
+			}
+			if len(w.rows) != 2 {
+				t.Fatalf("rows = %v, want header and one row", w.rows)
+			}
+			if !reflect.DeepEqual(w.rows[0], tt.wantHeader) {
+				t.Errorf("header = %v, want %v", w.rows[0], tt.wantHeader)
+			}
+			if !reflect.DeepEqual(w.rows[1], tt.wantRow) {
+				t.Errorf("row = %v, want %v", w.rows[1], tt.wantRow)
+			}
+		})
+	}
+}
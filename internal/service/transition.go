@@ -0,0 +1,179 @@
+package service
+
+import (
+	"ProtectedArea/internal/model"
+	"sort"
+)
+
+// transitionCell 矩阵中的一个单元 (某前地类流向某后地类)
+type transitionCell struct {
+	HLX        string  `json:"hlx"`
+	Count      int64   `json:"count"`
+	Area       float64 `json:"area"`
+	CountRatio float64 `json:"count_ratio"` // 占该前地类个数的比例 (%)
+	AreaRatio  float64 `json:"area_ratio"`  // 占该前地类面积的比例 (%)
+}
+
+// transitionRow 矩阵的一行 (一个前地类)
+type transitionRow struct {
+	QLX        string           `json:"qlx"`
+	Count      int64            `json:"count"`
+	Area       float64          `json:"area"`
+	CountRatio float64          `json:"count_ratio"` // 占全部个数的比例 (%)
+	AreaRatio  float64          `json:"area_ratio"`  // 占全部面积的比例 (%)
+	Targets    []transitionCell `json:"targets"`
+}
+
+// transitionTotal 列合计 (一个后地类)
+type transitionTotal struct {
+	HLX        string  `json:"hlx"`
+	Count      int64   `json:"count"`
+	Area       float64 `json:"area"`
+	CountRatio float64 `json:"count_ratio"`
+	AreaRatio  float64 `json:"area_ratio"`
+}
+
+// sankeyNode 桑基图节点，前后地类同名时用后缀区分，避免出现环
+type sankeyNode struct {
+	Name      string `json:"name"`
+	LandClass string `json:"land_class"`
+	Side      string `json:"side"` // source: 前地类; target: 后地类
+}
+
+// sankeyLink 桑基图连线
+type sankeyLink struct {
+	Source string  `json:"source"`
+	Target string  `json:"target"`
+	Value  float64 `json:"value"` // 按 metric 取个数或面积
+	Count  int64   `json:"count"`
+	Area   float64 `json:"area"`
+}
+
+// GetTransitionMatrix 地类流转矩阵 Service
+func (s *natureService) GetTransitionMatrix(req model.TransitionMatrixRequest) (map[string]interface{}, error) {
+	if req.Format == "" {
+		req.Format = "matrix"
+	}
+	if req.Metric == "" {
+		req.Metric = "count"
+	}
+	if req.Format != "matrix" && req.Format != "sankey" {
+		return nil, newValidationError("format 只能是 matrix 或 sankey")
+	}
+	if req.Metric != "count" && req.Metric != "area" {
+		return nil, newValidationError("metric 只能是 count 或 area")
+	}
+
+	pairs, err := s.store.GetTransitionMatrix(req.NatureQueryRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	response := map[string]interface{}{
+		"year":   req.Year,
+		"format": req.Format,
+	}
+	if req.Format == "sankey" {
+		nodes, links := buildSankey(pairs, req.Metric)
+		response["metric"] = req.Metric
+		response["nodes"] = nodes
+		response["links"] = links
+		return response, nil
+	}
+
+	rows, columns, totalCount, totalArea := buildTransitionMatrix(pairs)
+	response["rows"] = rows
+	response["columns"] = columns
+	response["total_count"] = totalCount
+	response["total_area"] = totalArea
+	return response, nil
+}
+
+// buildTransitionMatrix 把 (QLX, HLX) 列表组装成按前地类分行的矩阵，并计算行/列合计和占比
+func buildTransitionMatrix(pairs []model.TransitionPairStat) ([]*transitionRow, []*transitionTotal, int64, float64) {
+	rowMap := make(map[string]*transitionRow)
+	colMap := make(map[string]*transitionTotal)
+	// 没有数据时输出空数组而不是 null
+	rows := []*transitionRow{}
+	columns := []*transitionTotal{}
+	var totalCount int64
+	var totalArea float64
+
+	// 1. 累加行、列合计 (pairs 已按 QLX, HLX 排序)
+	for _, p := range pairs {
+		row, ok := rowMap[p.QLX]
+		if !ok {
+			row = &transitionRow{QLX: p.QLX, Targets: []transitionCell{}}
+			rowMap[p.QLX] = row
+			rows = append(rows, row)
+		}
+		row.Count += p.Count
+		row.Area += p.Area
+		row.Targets = append(row.Targets, transitionCell{HLX: p.HLX, Count: p.Count, Area: p.Area})
+
+		col, ok := colMap[p.HLX]
+		if !ok {
+			col = &transitionTotal{HLX: p.HLX}
+			colMap[p.HLX] = col
+			columns = append(columns, col)
+		}
+		col.Count += p.Count
+		col.Area += p.Area
+
+		totalCount += p.Count
+		totalArea += p.Area
+	}
+
+	// 2. 计算占比: 单元格相对所在行，行/列合计相对总计
+	for _, row := range rows {
+		for i := range row.Targets {
+			row.Targets[i].CountRatio = ratio(float64(row.Targets[i].Count), float64(row.Count))
+			row.Targets[i].AreaRatio = ratio(row.Targets[i].Area, row.Area)
+		}
+		row.CountRatio = ratio(float64(row.Count), float64(totalCount))
+		row.AreaRatio = ratio(row.Area, totalArea)
+	}
+	for _, col := range columns {
+		col.CountRatio = ratio(float64(col.Count), float64(totalCount))
+		col.AreaRatio = ratio(col.Area, totalArea)
+	}
+	sort.Slice(columns, func(i, j int) bool { return columns[i].HLX < columns[j].HLX })
+
+	return rows, columns, totalCount, totalArea
+}
+
+// buildSankey 生成桑基图的 nodes/links，节点名为 "地类(前)" / "地类(后)"
+func buildSankey(pairs []model.TransitionPairStat, metric string) ([]sankeyNode, []sankeyLink) {
+	nodes := []sankeyNode{}
+	links := []sankeyLink{}
+	seen := make(map[string]bool)
+
+	addNode := func(landClass, side, suffix string) string {
+		name := landClass + suffix
+		if !seen[name] {
+			seen[name] = true
+			nodes = append(nodes, sankeyNode{Name: name, LandClass: landClass, Side: side})
+		}
+		return name
+	}
+
+	for _, p := range pairs {
+		source := addNode(p.QLX, "source", "(前)")
+		target := addNode(p.HLX, "target", "(后)")
+
+		value := float64(p.Count)
+		if metric == "area" {
+			value = p.Area
+		}
+		links = append(links, sankeyLink{Source: source, Target: target, Value: value, Count: p.Count, Area: p.Area})
+	}
+	return nodes, links
+}
+
+// ratio 计算百分比，分母为 0 时返回 0
+func ratio(part, total float64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return part / total * 100
+}
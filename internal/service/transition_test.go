@@ -0,0 +1,143 @@
+package service
+
+import (
+	"ProtectedArea/internal/model"
+	"ProtectedArea/internal/store"
+	"encoding/json"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// fakeTransitionStore 返回固定的流转统计
+type fakeTransitionStore struct {
+	store.NatureStore
+	pairs []model.TransitionPairStat
+}
+
+func (f *fakeTransitionStore) GetTransitionMatrix(req model.NatureQueryRequest) ([]model.TransitionPairStat, error) {
+	return f.pairs, nil
+}
+
+func TestBuildTransitionMatrix(t *testing.T) {
+	pairs := []model.TransitionPairStat{
+		{QLX: "林地", HLX: "建设用地", Count: 3, Area: 6},
+		{QLX: "林地", HLX: "耕地", Count: 1, Area: 2},
+		{QLX: "草地", HLX: "建设用地", Count: 4, Area: 12},
+	}
+
+	rows, columns, totalCount, totalArea := buildTransitionMatrix(pairs)
+	if totalCount != 8 || totalArea != 20 {
+		t.Fatalf("total = %d, %v, want 8, 20", totalCount, totalArea)
+	}
+
+	// 行按 pairs 的顺序，单元格占比相对所在行
+	if len(rows) != 2 || rows[0].QLX != "林地" || rows[1].QLX != "草地" {
+		t.Fatalf("rows = %+v", rows)
+	}
+	if rows[0].Count != 4 || rows[0].Area != 8 || rows[0].CountRatio != 50 || rows[0].AreaRatio != 40 {
+		t.Errorf("row 林地 = %+v", *rows[0])
+	}
+	if got := rows[0].Targets[0]; got.HLX != "建设用地" || got.CountRatio != 75 || got.AreaRatio != 75 {
+		t.Errorf("cell 林地->建设用地 = %+v", got)
+	}
+
+	// 列按后地类名称排序，占比相对总计
+	var hlx []string
+	for _, col := range columns {
+		hlx = append(hlx, col.HLX)
+	}
+	if want := []string{"建设用地", "耕地"}; !reflect.DeepEqual(hlx, want) {
+		t.Errorf("columns = %q, want %q", hlx, want)
+	}
+	if col := columns[0]; col.Count != 7 || col.Area != 18 || col.CountRatio != 87.5 || col.AreaRatio != 90 {
+		t.Errorf("column 建设用地 = %+v", *col)
+	}
+
+	// 行、列占比各自合计 100%
+	var rowSum, colSum float64
+	for _, row := range rows {
+		rowSum += row.CountRatio
+	}
+	for _, col := range columns {
+		colSum += col.CountRatio
+	}
+	if math.Abs(rowSum-100) > 1e-9 || math.Abs(colSum-100) > 1e-9 {
+		t.Errorf("ratio sums = %v, %v, want 100", rowSum, colSum)
+	}
+}
+
+func TestBuildSankey(t *testing.T) {
+	pairs := []model.TransitionPairStat{
+		{QLX: "林地", HLX: "林地", Count: 2, Area: 5},
+		{QLX: "林地", HLX: "耕地", Count: 1, Area: 3},
+		{QLX: "耕地", HLX: "林地", Count: 4, Area: 1},
+	}
+
+	tests := []struct {
+		metric     string
+		wantValues []float64
+	}{
+		{metric: "count", wantValues: []float64{2, 1, 4}},
+		{metric: "area", wantValues: []float64{5, 3, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.metric, func(t *testing.T) {
+			nodes, links := buildSankey(pairs, tt.metric)
+
+			// 同名地类按前/后拆成不同节点，不会形成环
+			var names []string
+			for _, n := range nodes {
+				names = append(names, n.Name)
+			}
+			if want := []string{"林地(前)", "林地(后)", "耕地(后)", "耕地(前)"}; !reflect.DeepEqual(names, want) {
+				t.Errorf("nodes = %q, want %q", names, want)
+			}
+			for _, l := range links {
+				if l.Source == l.Target {
+					t.Errorf("link %+v is a cycle", l)
+				}
+			}
+
+			var values []float64
+			for _, l := range links {
+				values = append(values, l.Value)
+			}
+			if !reflect.DeepEqual(values, tt.wantValues) {
+				t.Errorf("values = %v, want %v", values, tt.wantValues)
+			}
+		})
+	}
+}
+
+func TestGetTransitionMatrixEmpty(t *testing.T) {
+	srv := NewNatureService(&fakeTransitionStore{}, nil, nil, nil, "")
+
+	for _, format := range []string{"matrix", "sankey"} {
+		t.Run(format, func(t *testing.T) {
+			result, err := srv.GetTransitionMatrix(model.TransitionMatrixRequest{Format: format})
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			data, err := json.Marshal(result)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// 没有数据时输出空数组，不能是 null
+			var body map[string]json.RawMessage
+			if err := json.Unmarshal(data, &body); err != nil {
+				t.Fatal(err)
+			}
+			keys := []string{"rows", "columns"}
+			if format == "sankey" {
+				keys = []string{"nodes", "links"}
+			}
+			for _, key := range keys {
+				if string(body[key]) != "[]" {
+					t.Errorf("%s = %s, want []", key, body[key])
+				}
+			}
+		})
+	}
+}
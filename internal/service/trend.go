@@ -0,0 +1,178 @@
+package service
+
+import (
+	"ProtectedArea/internal/model"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// trendBreakdownColumns 趋势分析的细分维度 -> 数据库字段名
+var trendBreakdownColumns = map[string]string{
+	"province":       "THSHENG",
+	"protected_type": "BHDLX",
+	"batch":          "PC",
+}
+
+// defaultTrendChangeTypes 未指定 change_type 时统计的变化地类，与旧版趋势接口保持一致
+var defaultTrendChangeTypes = []string{"资源损毁", "恢复治理"}
+
+// maxTrendYears 年份区间最多允许的年数，防止补零时生成过长的序列
+const maxTrendYears = 100
+
+// TrendSeries 趋势图中的一条折线
+type TrendSeries struct {
+	Name       string    `json:"name"`        // 图例名称: 变化地类 或 变化地类-细分值
+	ChangeType string    `json:"change_type"` // 变化地类
+	Group      string    `json:"group"`       // 细分值，不细分时为空
+	Data       []float64 `json:"data"`        // 与 years 一一对应的取值，没有数据的年份为 0
+}
+
+// GetTrendAnalysis 趋势分析: 按年份统计各变化地类的个数或面积，可按省份/保护地类型/批次细分
+// 返回格式: {"metric": "count", "breakdown": "", "years": ["2020", ...], "series": [{"name": "资源损毁", "data": [352, ...]}]}
+func (s *natureService) GetTrendAnalysis(req model.TrendQueryRequest) (map[string]interface{}, error) {
+	// 1. 参数校验和转换
+	if req.Metric == "" {
+		req.Metric = "count"
+	}
+	if req.Metric != "count" && req.Metric != "area" {
+		return nil, newValidationError("metric 只能是 count 或 area")
+	}
+
+	changeTypes := defaultTrendChangeTypes
+	if strings.TrimSpace(req.ChangeType) != "" {
+		changeTypes = parseChangeTypes(req.ChangeType)
+	}
+	filter := model.TrendFilter{
+		ChangeTypes:   changeTypes,
+		StartYear:     req.StartYear,
+		EndYear:       req.EndYear,
+		ProtectedType: req.ProtectedType,
+		RegionName:    req.RegionName,
+		UserScope:     req.UserScope,
+	}
+	for _, y := range []string{req.StartYear, req.EndYear} {
+		if y != "" && !yearPattern.MatchString(y) {
+			return nil, newValidationError("年份必须是四位数字: %s", y)
+		}
+	}
+	if req.StartYear != "" && req.EndYear != "" && req.StartYear > req.EndYear {
+		return nil, newValidationError("start_year 不能大于 end_year")
+	}
+	if req.Breakdown != "" {
+		col, ok := trendBreakdownColumns[req.Breakdown]
+		if !ok {
+			return nil, newValidationError("无效的细分维度(breakdown): %s", req.Breakdown)
+		}
+		filter.GroupCol = col
+	}
+	if req.RegionName != "" {
+		col, ok := scopeColumns[req.Scope]
+		if !ok {
+			return nil, newValidationError("无效的查询范围(scope): %s", req.Scope)
+		}
+		filter.RegionCol = col
+	}
+
+	// 2. 查询原始分组数据
+	rawStats, err := s.store.GetTrendStats(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. 按 (变化地类, 细分值) 聚合成序列
+	// 批次需要先把原始 PC 归并为标准批次名称，所以这里统一在内存中累加
+	type seriesKey struct{ changeType, group string }
+	values := make(map[seriesKey]map[string]float64)
+	yearSet := make(map[string]bool)
+	for _, item := range rawStats {
+		group := item.GroupName
+		switch req.Breakdown {
+		case "batch":
+			group = s.batchName(group, item.Year)
+		case "":
+		default:
+			if group == "" {
+				group = "未知"
+			}
+		}
+
+		key := seriesKey{changeType: item.BHDL, group: group}
+		if values[key] == nil {
+			values[key] = make(map[string]float64)
+		}
+		if req.Metric == "area" {
+			values[key][item.Year] += item.Area
+		} else {
+			values[key][item.Year] += float64(item.Count)
+		}
+		yearSet[item.Year] = true
+	}
+
+	// 4. 生成年份轴: 指定了完整区间时补全区间内的所有年份，否则使用有数据的年份
+	years := buildTrendYears(req.StartYear, req.EndYear, yearSet)
+
+	series := make([]TrendSeries, 0, len(values))
+	for key, byYear := range values {
+		data := make([]float64, len(years))
+		for i, y := range years {
+			data[i] = byYear[y]
+		}
+		name := key.changeType
+		if key.group != "" {
+			name += "-" + key.group
+		}
+		series = append(series, TrendSeries{Name: name, ChangeType: key.changeType, Group: key.group, Data: data})
+	}
+	sort.Slice(series, func(i, j int) bool {
+		if series[i].ChangeType != series[j].ChangeType {
+			return series[i].ChangeType < series[j].ChangeType
+		}
+		return series[i].Group < series[j].Group
+	})
+
+	return map[string]interface{}{
+		"metric":    req.Metric,
+		"breakdown": req.Breakdown,
+		"years":     years,
+		"series":    series,
+	}, nil
+}
+
+// parseChangeTypes 解析逗号分隔的变化地类，空或 all 返回 nil (表示全部)
+func parseChangeTypes(raw string) []string {
+	var result []string
+	for _, t := range strings.Split(raw, ",") {
+		t = strings.TrimSpace(t)
+		if t == "" {
+			continue
+		}
+		if strings.EqualFold(t, "all") {
+			return nil
+		}
+		result = append(result, t)
+	}
+	return result
+}
+
+// buildTrendYears 生成有序的年份轴
+func buildTrendYears(start, end string, present map[string]bool) []string {
+	if start != "" && end != "" {
+		from, _ := strconv.Atoi(start)
+		to, _ := strconv.Atoi(end)
+		if to-from < maxTrendYears {
+			years := make([]string, 0, to-from+1)
+			for y := from; y <= to; y++ {
+				years = append(years, strconv.Itoa(y))
+			}
+			return years
+		}
+	}
+
+	years := make([]string, 0, len(present))
+	for y := range present {
+		years = append(years, y)
+	}
+	sort.Strings(years)
+	return years
+}
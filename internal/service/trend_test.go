@@ -0,0 +1,96 @@
+package service
+
+import (
+	"ProtectedArea/internal/model"
+	"ProtectedArea/internal/store"
+	"reflect"
+	"testing"
+)
+
+// fakeTrendStore 记录查询条件并返回固定的统计结果
+type fakeTrendStore struct {
+	store.NatureStore
+	filter model.TrendFilter
+	stats  []model.StatResult
+}
+
+func (f *fakeTrendStore) GetTrendStats(filter model.TrendFilter) ([]model.StatResult, error) {
+	f.filter = filter
+	return f.stats, nil
+}
+
+func TestParseChangeTypes(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want []string
+	}{
+		{raw: "", want: nil},
+		{raw: "all", want: nil},
+		{raw: "资源损毁,ALL", want: nil},
+		{raw: " 资源损毁 ,, 恢复治理", want: []string{"资源损毁", "恢复治理"}},
+	}
+	for _, tt := range tests {
+		if got := parseChangeTypes(tt.raw); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseChangeTypes(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestGetTrendAnalysisChangeTypes(t *testing.T) {
+	tests := []struct {
+		name       string
+		changeType string
+		want       []string
+	}{
+		{name: "未指定时使用默认变化地类", changeType: "", want: []string{"资源损毁", "恢复治理"}},
+		{name: "只有空白时使用默认变化地类", changeType: "  ", want: []string{"资源损毁", "恢复治理"}},
+		{name: "all 表示全部", changeType: "all", want: nil},
+		{name: "指定变化地类", changeType: "其他变化", want: []string{"其他变化"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeTrendStore{}
+			srv := NewNatureService(fake, nil, nil, nil, "")
+			if _, err := srv.GetTrendAnalysis(model.TrendQueryRequest{ChangeType: tt.changeType}); err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			if !reflect.DeepEqual(fake.filter.ChangeTypes, tt.want) {
+				t.Errorf("change types = %q, want %q", fake.filter.ChangeTypes, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetTrendAnalysisSeries(t *testing.T) {
+	fake := &fakeTrendStore{stats: []model.StatResult{
+		{Year: "2021", BHDL: "资源损毁", GroupName: "河北省", Count: 2, Area: 1.5},
+		{Year: "2023", BHDL: "资源损毁", GroupName: "河北省", Count: 5, Area: 3},
+		{Year: "2022", BHDL: "恢复治理", GroupName: "", Count: 1, Area: 0.5},
+	}}
+	srv := NewNatureService(fake, nil, nil, nil, "")
+
+	result, err := srv.GetTrendAnalysis(model.TrendQueryRequest{
+		StartYear: "2020",
+		EndYear:   "2023",
+		Metric:    "area",
+		Breakdown: "province",
+	})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	// 指定完整区间时补全没有数据的年份
+	if want := []string{"2020", "2021", "2022", "2023"}; !reflect.DeepEqual(result["years"], want) {
+		t.Errorf("years = %v, want %v", result["years"], want)
+	}
+	want := []TrendSeries{
+		{Name: "恢复治理-未知", ChangeType: "恢复治理", Group: "未知", Data: []float64{0, 0, 0.5, 0}},
+		{Name: "资源损毁-河北省", ChangeType: "资源损毁", Group: "河北省", Data: []float64{0, 1.5, 0, 3}},
+	}
+	if got := result["series"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("series = %+v, want %+v", got, want)
+	}
+	if fake.filter.GroupCol != "THSHENG" {
+		t.Errorf("group column = %q, want THSHENG", fake.filter.GroupCol)
+	}
+}
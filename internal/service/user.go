@@ -0,0 +1,145 @@
+package service
+
+import (
+	"ProtectedArea/internal/model"
+	"ProtectedArea/internal/store"
+	"ProtectedArea/pkg/errcode"
+	"errors"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// minPasswordLength 密码最小长度
+const minPasswordLength = 8
+
+type UserService interface {
+	List() ([]model.User, error)
+	Create(input model.UserInput) (*model.User, error)
+	Update(id uint, input model.UserInput) (*model.User, error)
+	Delete(id uint) error
+	// ResetAPIKey 为用户生成新的 API Key (旧的立即失效)，返回明文
+	ResetAPIKey(id uint) (string, error)
+}
+
+type userService struct {
+	store store.UserStore
+}
+
+func NewUserService(s store.UserStore) UserService {
+	return &userService{store: s}
+}
+
+func (s *userService) List() ([]model.User, error) {
+	return s.store.List()
+}
+
+func (s *userService) Create(input model.UserInput) (*model.User, error) {
+	if len(input.Password) < minPasswordLength {
+		return nil, newValidationError("密码长度不能少于 %d 位", minPasswordLength)
+	}
+
+	u := &model.User{}
+	if err := applyUserInput(u, input); err != nil {
+		return nil, err
+	}
+	if err := s.store.Create(u); err != nil {
+		return nil, translateUserError(err)
+	}
+	return u, nil
+}
+
+func (s *userService) Update(id uint, input model.UserInput) (*model.User, error) {
+	u, err := s.store.GetByID(id)
+	if err != nil {
+		return nil, translateUserError(err)
+	}
+	if input.Password != "" && len(input.Password) < minPasswordLength {
+		return nil, newValidationError("密码长度不能少于 %d 位", minPasswordLength)
+	}
+	if err := applyUserInput(u, input); err != nil {
+		return nil, err
+	}
+	if err := s.store.Update(u); err != nil {
+		return nil, translateUserError(err)
+	}
+	return u, nil
+}
+
+func (s *userService) Delete(id uint) error {
+	return translateUserError(s.store.Delete(id))
+}
+
+func (s *userService) ResetAPIKey(id uint) (string, error) {
+	u, err := s.store.GetByID(id)
+	if err != nil {
+		return "", translateUserError(err)
+	}
+	key, hash, err := newAPIKey()
+	if err != nil {
+		return "", err
+	}
+	u.APIKeyHash = hash
+	if err := s.store.Update(u); err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+// applyUserInput 校验角色与行政区的对应关系，并把请求体复制到实体上
+// 省级用户必须指定省，市级用户必须指定省和市，县级用户必须指定省、市、县；
+// 高于该级别的行政区字段会被清空，避免残留的范围限制
+func applyUserInput(u *model.User, input model.UserInput) error {
+	input.Username = strings.TrimSpace(input.Username)
+	if input.Username == "" {
+		return newValidationError("用户名(username)不能为空")
+	}
+
+	switch input.Role {
+	case model.RoleAdmin, model.RoleNational:
+		input.Province, input.City, input.County = "", "", ""
+	case model.RoleProvince:
+		if input.Province == "" {
+			return newValidationError("省级用户必须指定 province")
+		}
+		input.City, input.County = "", ""
+	case model.RoleCity:
+		if input.Province == "" || input.City == "" {
+			return newValidationError("市级用户必须指定 province 和 city")
+		}
+		input.County = ""
+	case model.RoleCounty:
+		if input.Province == "" || input.City == "" || input.County == "" {
+			return newValidationError("县级用户必须指定 province、city 和 county")
+		}
+	default:
+		return newValidationError("无效的角色(role): %s", input.Role)
+	}
+
+	if input.Password != "" {
+		hash, err := hashPassword(input.Password)
+		if err != nil {
+			return err
+		}
+		u.PasswordHash = hash
+	}
+	u.Username = input.Username
+	u.Role = input.Role
+	u.Province = input.Province
+	u.City = input.City
+	u.County = input.County
+	u.Disabled = input.Disabled
+	return nil
+}
+
+// translateUserError 把数据库错误转换为业务错误
+func translateUserError(err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return errcode.UserNotFound
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return errcode.UserExists
+	default:
+		return err
+	}
+}
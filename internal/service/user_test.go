@@ -0,0 +1,91 @@
+package service
+
+import (
+	"ProtectedArea/internal/model"
+	"ProtectedArea/pkg/errcode"
+	"errors"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestApplyUserInput(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     model.UserInput
+		wantScope model.RegionScope
+		wantErr   bool
+	}{
+		{
+			name:  "管理员清空行政区",
+			input: model.UserInput{Username: "admin", Role: model.RoleAdmin, Province: "河北省", City: "保定市"},
+		},
+		{
+			name:  "国家级用户清空行政区",
+			input: model.UserInput{Username: "nation", Role: model.RoleNational, County: "涞水县"},
+		},
+		{
+			name:      "省级用户清空市县",
+			input:     model.UserInput{Username: " hebei ", Role: model.RoleProvince, Province: "河北省", City: "保定市", County: "涞水县"},
+			wantScope: model.RegionScope{Province: "河北省"},
+		},
+		{
+			name:      "市级用户清空县",
+			input:     model.UserInput{Username: "baoding", Role: model.RoleCity, Province: "河北省", City: "保定市", County: "涞水县"},
+			wantScope: model.RegionScope{Province: "河北省", City: "保定市"},
+		},
+		{
+			name:      "县级用户",
+			input:     model.UserInput{Username: "laishui", Role: model.RoleCounty, Province: "河北省", City: "保定市", County: "涞水县"},
+			wantScope: model.RegionScope{Province: "河北省", City: "保定市", County: "涞水县"},
+		},
+		{name: "用户名为空", input: model.UserInput{Username: "  ", Role: model.RoleAdmin}, wantErr: true},
+		{name: "无效角色", input: model.UserInput{Username: "u", Role: "root"}, wantErr: true},
+		{name: "省级用户缺少省", input: model.UserInput{Username: "u", Role: model.RoleProvince}, wantErr: true},
+		{name: "市级用户缺少市", input: model.UserInput{Username: "u", Role: model.RoleCity, Province: "河北省"}, wantErr: true},
+		{name: "县级用户缺少县", input: model.UserInput{Username: "u", Role: model.RoleCounty, Province: "河北省", City: "保定市"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := &model.User{PasswordHash: "old"}
+			err := applyUserInput(u, tt.input)
+			if tt.wantErr {
+				if !errors.Is(err, errcode.InvalidParams) {
+					t.Fatalf("err = %v, want errcode.InvalidParams", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			if u.Role != tt.input.Role || u.Username == "" || u.Username[0] == ' ' {
+				t.Errorf("user = %+v", u)
+			}
+			// 高于角色级别的行政区字段被清空，不会残留范围限制
+			if u.Province != tt.wantScope.Province || u.City != tt.wantScope.City || u.County != tt.wantScope.County {
+				t.Errorf("region = %q/%q/%q, want %+v", u.Province, u.City, u.County, tt.wantScope)
+			}
+			if u.Scope() != tt.wantScope {
+				t.Errorf("Scope() = %+v, want %+v", u.Scope(), tt.wantScope)
+			}
+			// 未提供密码时不修改原密码
+			if u.PasswordHash != "old" {
+				t.Errorf("password hash changed without password")
+			}
+		})
+	}
+}
+
+func TestApplyUserInputPassword(t *testing.T) {
+	u := &model.User{}
+	if err := applyUserInput(u, model.UserInput{Username: "u", Password: "secret-pass", Role: model.RoleAdmin}); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if u.PasswordHash == "" || u.PasswordHash == "secret-pass" {
+		t.Fatalf("password hash = %q, want bcrypt hash", u.PasswordHash)
+	}
+	if bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte("secret-pass")) != nil {
+		t.Errorf("hash does not match the password")
+	}
+}
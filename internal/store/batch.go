@@ -0,0 +1,75 @@
+package store
+
+import (
+	"ProtectedArea/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// BatchStore 批次登记表的数据访问接口
+type BatchStore interface {
+	// List 按 sort_order、id 排序返回全部批次规则
+	List() ([]model.Batch, error)
+	Count() (int64, error)
+	GetByID(id uint) (*model.Batch, error)
+	Create(b *model.Batch) error
+	// CreateInBatches 一次写入多条 (用于初始化默认批次)
+	CreateInBatches(batches []model.Batch) error
+	Update(b *model.Batch) error
+	Delete(id uint) error
+}
+
+type batchStore struct {
+	db *gorm.DB
+}
+
+// NewBatchStore 构造函数
+func NewBatchStore(db *gorm.DB) BatchStore {
+	return &batchStore{db: db}
+}
+
+func (s *batchStore) List() ([]model.Batch, error) {
+	var batches []model.Batch
+	err := s.db.Order("sort_order, id").Find(&batches).Error
+	return batches, err
+}
+
+func (s *batchStore) Count() (int64, error) {
+	var total int64
+	err := s.db.Model(&model.Batch{}).Count(&total).Error
+	return total, err
+}
+
+// GetByID 按主键查询，不存在时返回 gorm.ErrRecordNotFound
+func (s *batchStore) GetByID(id uint) (*model.Batch, error) {
+	var b model.Batch
+	if err := s.db.First(&b, id).Error; err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+func (s *batchStore) Create(b *model.Batch) error {
+	return s.db.Create(b).Error
+}
+
+func (s *batchStore) CreateInBatches(batches []model.Batch) error {
+	return s.db.CreateInBatches(batches, 100).Error
+}
+
+// Update 全量更新 (包括零值字段)
+func (s *batchStore) Update(b *model.Batch) error {
+	return s.db.Save(b).Error
+}
+
+// Delete 删除，不存在时返回 gorm.ErrRecordNotFound
+func (s *batchStore) Delete(id uint) error {
+	result := s.db.Delete(&model.Batch{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
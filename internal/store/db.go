@@ -0,0 +1,38 @@
+package store
+
+import (
+	"ProtectedArea/internal/config"
+	"ProtectedArea/internal/model"
+	"fmt"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+// NewDB 根据配置打开数据库连接并设置连接池
+func NewDB(cfg config.DatabaseConfig) (*gorm.DB, error) {
+	// TranslateError: 把唯一键冲突等驱动错误转换为 gorm.ErrDuplicatedKey 等通用错误
+	db, err := gorm.Open(mysql.Open(cfg.GetDSN()), &gorm.Config{TranslateError: true})
+	if err != nil {
+		return nil, fmt.Errorf("数据库连接失败: %w", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("获取数据库连接池失败: %w", err)
+	}
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+
+	return db, nil
+}
+
+// AutoMigrate 创建/更新由本服务维护的表
+// nature_data 由外部导入，不在此处迁移
+func AutoMigrate(db *gorm.DB) error {
+	if err := db.AutoMigrate(&model.ProtectedArea{}, &model.User{}, &model.Batch{}, &model.SpotImage{}, &model.ImageAudit{}); err != nil {
+		return fmt.Errorf("数据表迁移失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package store
+
+import (
+	"ProtectedArea/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// ImageStore 图斑图片索引 (spot_image) 的数据访问接口
+type ImageStore interface {
+	// List 返回全部索引 (用于重新扫描时对比)
+	List() ([]model.SpotImage, error)
+	// ListByTBBH 返回某个图斑的全部图片，按序号、路径排序
+	ListByTBBH(tbbh string) ([]model.SpotImage, error)
+	// ListByTBBHs 批量查询多个图斑的图片，按 tbbh、序号、路径排序
+	ListByTBBHs(tbbhs []string) ([]model.SpotImage, error)
+	// GetByID 按主键查询，不存在时返回 gorm.ErrRecordNotFound
+	GetByID(id uint) (*model.SpotImage, error)
+	// Sync 在同一个事务中新增、更新和删除索引
+	Sync(creates, updates []model.SpotImage, deletes []uint) error
+
+	// Create/Update/Delete 修改单张图片的索引，并在同一个事务中写入变更记录
+	Create(img *model.SpotImage, audit *model.ImageAudit) error
+	Update(img *model.SpotImage, audit *model.ImageAudit) error
+	Delete(img *model.SpotImage, audit *model.ImageAudit) error
+	// ListAudits 变更记录 (带分页，最新的在前)，tbbh 为空表示全部
+	ListAudits(tbbh string, page, pageSize int) ([]model.ImageAudit, int64, error)
+}
+
+type imageStore struct {
+	db *gorm.DB
+}
+
+// NewImageStore 构造函数
+func NewImageStore(db *gorm.DB) ImageStore {
+	return &imageStore{db: db}
+}
+
+func (s *imageStore) List() ([]model.SpotImage, error) {
+	var images []model.SpotImage
+	err := s.db.Order("id").Find(&images).Error
+	return images, err
+}
+
+func (s *imageStore) ListByTBBH(tbbh string) ([]model.SpotImage, error) {
+	var images []model.SpotImage
+	err := s.db.Where("tbbh = ?", tbbh).Order("seq, path").Find(&images).Error
+	return images, err
+}
+
+func (s *imageStore) ListByTBBHs(tbbhs []string) ([]model.SpotImage, error) {
+	var images []model.SpotImage
+	for start := 0; start < len(tbbhs); start += inQueryChunk {
+		end := min(start+inQueryChunk, len(tbbhs))
+
+		var chunk []model.SpotImage
+		err := s.db.Where("tbbh IN ?", tbbhs[start:end]).Order("tbbh, seq, path").Find(&chunk).Error
+		if err != nil {
+			return nil, err
+		}
+		images = append(images, chunk...)
+	}
+	return images, nil
+}
+
+func (s *imageStore) GetByID(id uint) (*model.SpotImage, error) {
+	var img model.SpotImage
+	if err := s.db.First(&img, id).Error; err != nil {
+		return nil, err
+	}
+	return &img, nil
+}
+
+func (s *imageStore) Sync(creates, updates []model.SpotImage, deletes []uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if len(creates) > 0 {
+			if err := tx.CreateInBatches(creates, 100).Error; err != nil {
+				return err
+			}
+		}
+		for i := range updates {
+			if err := tx.Save(&updates[i]).Error; err != nil {
+				return err
+			}
+		}
+		for start := 0; start < len(deletes); start += inQueryChunk {
+			end := min(start+inQueryChunk, len(deletes))
+			if err := tx.Delete(&model.SpotImage{}, deletes[start:end]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *imageStore) Create(img *model.SpotImage, audit *model.ImageAudit) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(img).Error; err != nil {
+			return err
+		}
+		audit.ImageID = img.ID
+		return tx.Create(audit).Error
+	})
+}
+
+func (s *imageStore) Update(img *model.SpotImage, audit *model.ImageAudit) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(img).Error; err != nil {
+			return err
+		}
+		return tx.Create(audit).Error
+	})
+}
+
+func (s *imageStore) Delete(img *model.SpotImage, audit *model.ImageAudit) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(img).Error; err != nil {
+			return err
+		}
+		return tx.Create(audit).Error
+	})
+}
+
+func (s *imageStore) ListAudits(tbbh string, page, pageSize int) ([]model.ImageAudit, int64, error) {
+	var audits []model.ImageAudit
+	var total int64
+
+	query := s.db.Model(&model.ImageAudit{})
+	if tbbh != "" {
+		query = query.Where("tbbh = ?", tbbh)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Order("id DESC").
+		Limit(pageSize).Offset((page - 1) * pageSize).
+		Find(&audits).Error
+	return audits, total, err
+}
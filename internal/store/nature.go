@@ -2,27 +2,90 @@ package store
 
 import (
 	"ProtectedArea/internal/model"
+	"fmt"
+	"sort"
+	"strings"
+
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // NatureStore 定义接口，方便后续扩展
 type NatureStore interface {
-	GetYearlyTrendStats() ([]model.StatResult, error)
+	// GetTrendStats 按年份、变化地类 (以及可选的细分列) 分组统计个数和面积
+	GetTrendStats(f model.TrendFilter) ([]model.StatResult, error)
 
-	GetSummaryByYear(year string) (int64, float64, error)
-	GetDamageStatsByBatch(year string) ([]model.BatchStatResult, error)
+	// GetSummaryByYear protected_type/province 为空表示不筛选
+	GetSummaryByYear(req model.OverviewQueryRequest) (int64, float64, error)
 	// GetRegionStats
 	// year: 年份
 	// groupCol: 要分组统计的目标列 (比如 THSHI)
 	// filterCol: 筛选条件的列名 (比如 THSHENG)，如果没有筛选则为空字符串
 	// filterVal: 筛选条件的值 (比如 "河北省")
-	GetRegionStats(year string, groupCol string, filterCol string, filterVal string) ([]model.RegionStatResult, error)
+	// scope: 当前用户的数据范围
+	GetRegionStats(year string, groupCol string, filterCol string, filterVal string, scope model.RegionScope) ([]model.RegionStatResult, error)
+
+	// GetRegionTreeStats 按 groupCols (THSHENG[, THSHI[, THXIAN]]) 分组统计，按行政区名称排序
+	GetRegionTreeStats(req model.RegionTreeRequest, groupCols []string) ([]model.RegionRow, error)
+	// GetRegionNames 列出出现过的行政区组合 (DISTINCT groupCols)，year 为空表示全部年份
+	GetRegionNames(year string, groupCols []string, scope model.RegionScope) ([]model.RegionRow, error)
 
+	// GetProtectedAreaStats PageSize <= 0 时不分页，返回全部分组 (用于导出)
 	GetProtectedAreaStats(req model.NatureQueryRequest) ([]model.ProtectedAreaStat, int64, error)
 	GetSpotList(req model.NatureQueryRequest) ([]model.SpotListItem, int64, error)
+	// GetSpotListColumns 与 GetSpotList 相同，但只查询 columns 中的列 (完整结构体中其它字段为零值)
+	GetSpotListColumns(req model.NatureQueryRequest, columns []string) ([]model.NatureData, int64, error)
+	// GetSpotListKeyset 图斑明细 (游标分页)，只查询 columns 中的列，不统计总数
+	GetSpotListKeyset(req model.NatureQueryRequest, columns []string, k model.Keyset) ([]model.NatureData, error)
+	// CountSpots 统计满足公共筛选条件的图斑个数
+	CountSpots(req model.NatureQueryRequest) (int64, error)
+	// GetRanking 按 groupCol 分组统计并按 orderBy 排序，返回前 limit 项
+	GetRanking(req model.NatureQueryRequest, groupCol, orderBy string, limit int) ([]model.RankingItem, error)
 	GetTransitionStats(req model.NatureQueryRequest) ([]model.TransitionStat, error)
+	// GetTransitionMatrix 按 (QLX, HLX) 分组统计
+	GetTransitionMatrix(req model.NatureQueryRequest) ([]model.TransitionPairStat, error)
 
 	GetLargeSpots(req model.AlertQueryRequest) ([]model.AlertSpotItem, int64, error)
+	// GetLargeSpotsKeyset 大图斑预警 (游标分页)，不统计总数
+	GetLargeSpotsKeyset(req model.AlertQueryRequest, k model.Keyset) ([]model.AlertSpotItem, error)
+	// CountLargeSpots 统计预警图斑个数
+	CountLargeSpots(req model.AlertQueryRequest) (int64, error)
+	// GetSpot 按 TBBH 查询单个图斑，不存在或不在 scope 范围内时返回 gorm.ErrRecordNotFound
+	GetSpot(tbbh string, scope model.RegionScope) (*model.NatureData, error)
+	// GetSpotsByTBBH 按 TBBH 批量查询图斑
+	GetSpotsByTBBH(tbbhs []string, scope model.RegionScope) ([]model.NatureData, error)
+	// GetSuccessorSpots 查询 SQTBBH 属于 tbbhs 的图斑 (即这些图斑的下一期)
+	GetSuccessorSpots(tbbhs []string, scope model.RegionScope) ([]model.NatureData, error)
+	// ListSpotKeys 全部图斑的 TBBH、年份、批次和省 (不含其它字段)，按 TBBH 排序
+	ListSpotKeys() ([]model.NatureData, error)
+	// GetRepeatChangeStats 按 保护地、上期变化地类、本期变化地类 统计重复变化 (SFCXBH=1) 的图斑
+	// 上期图斑不存在或不在 req.UserScope 范围内时上期变化地类为空
+	GetRepeatChangeStats(req model.NatureQueryRequest) ([]model.RepeatChangeTransitionStat, error)
+	// GetRepeatChangeSpots 查询重复变化的图斑 (带分页，默认按 TBBH 排序)，name 为空表示不限保护地
+	GetRepeatChangeSpots(req model.NatureQueryRequest, name string) ([]model.NatureData, int64, error)
+
+	// GetAttributionDiffStats 按 groupCol 分组统计核实值与原报值不一致的图斑个数，按不一致个数倒序
+	GetAttributionDiffStats(req model.NatureQueryRequest, groupCol string) ([]model.AttributionDiffStat, error)
+	// GetAttributionDiffSpots 查询 fields 中任一字段不一致的图斑 (带分页，按 TBBH 排序)
+	// 每条记录的 DiffFields 为不一致的字段，与筛选使用同一个 SQL 条件 (比较规则取决于列的排序规则，例如忽略大小写和尾部空格)
+	GetAttributionDiffSpots(req model.NatureQueryRequest, fields []model.AttributionField) ([]model.AttributionDiffSpot, int64, error)
+	// StreamLargeSpots 逐条遍历全部预警图斑 (忽略分页参数，默认按面积从大到小)
+	StreamLargeSpots(req model.AlertQueryRequest, fn func(*model.AlertSpotItem) error) error
+
+	// GetSpotsInBBox 查询范围内的图斑 (带分页，默认按 TBBH 排序)
+	GetSpotsInBBox(req model.NatureQueryRequest, bbox model.BBox) ([]model.SpotLocationItem, int64, error)
+	// GetSpotsWithinRadius 查询距 (lng, lat) 不超过 radius 米的图斑 (带分页，默认按距离由近到远排序)
+	GetSpotsWithinRadius(req model.NatureQueryRequest, lng, lat, radius float64) ([]model.SpotLocationItem, int64, error)
+
+	// GetSpotClusters 把范围内的图斑按 cellSize (度) 划分网格聚合
+	GetSpotClusters(req model.NatureQueryRequest, bbox model.BBox, cellSize float64) ([]model.SpotCluster, error)
+
+	// StreamSpots 按 q.OrderBy (为空时按 TBBH) 顺序逐条遍历符合条件的完整图斑记录，fn 返回错误时停止遍历
+	StreamSpots(q model.SpotStreamQuery, fn func(*model.NatureData) error) error
+
+	// UpsertSpots 按 TBBH 插入或更新一批图斑，整批在同一个事务中完成
+	// 已存在的图斑只更新 columns 中的列，其它列保持原值
+	UpsertSpots(spots []model.NatureData, columns []string) error
 }
 
 // natureStore 结构体实现接口
@@ -35,51 +98,67 @@ func NewNatureStore(db *gorm.DB) NatureStore {
 	return &natureStore{db: db}
 }
 
-// GetYearlyTrendStats 执行具体的 SQL 统计查询
-func (s *natureStore) GetYearlyTrendStats() ([]model.StatResult, error) {
+// GetTrendStats 执行具体的 SQL 统计查询
+func (s *natureStore) GetTrendStats(f model.TrendFilter) ([]model.StatResult, error) {
 	var results []model.StatResult
 
-	// SQL: SELECT year, BHDL, count(*) FROM nature_data WHERE ... GROUP BY ...
-	err := s.db.Model(&model.NatureData{}).
-		Select("year, BHDL, count(*) as count").
-		Where("BHDL IN ?", []string{"资源损毁", "恢复治理"}).
-		Group("year, BHDL").
+	// SQL: SELECT year, BHDL, [groupCol,] count(*), sum(BHMJ) FROM nature_data WHERE ... GROUP BY ...
+	selectCols := "year, BHDL, count(*) as count, COALESCE(sum(BHMJ), 0) as area"
+	groupCols := "year, BHDL"
+	if f.GroupCol != "" {
+		selectCols += ", " + f.GroupCol + " as group_name"
+		groupCols += ", " + f.GroupCol
+	}
+
+	query := s.db.Model(&model.NatureData{}).Select(selectCols)
+	if len(f.ChangeTypes) > 0 {
+		query = query.Where("BHDL IN ?", f.ChangeTypes)
+	}
+	if f.StartYear != "" {
+		query = query.Where("year >= ?", f.StartYear)
+	}
+	if f.EndYear != "" {
+		query = query.Where("year <= ?", f.EndYear)
+	}
+	if f.RegionCol != "" && f.RegionName != "" {
+		query = query.Where(f.RegionCol+" = ?", f.RegionName)
+	}
+	if f.ProtectedType != "" {
+		query = query.Where("BHDLX = ?", f.ProtectedType)
+	}
+	query = applyRegionScope(query, f.UserScope)
+
+	err := query.Group(groupCols).
+		Order("year").
 		Scan(&results).Error
 
 	return results, err
 }
 
 // GetSummaryByYear 1. 获取某年的总图斑数和总面积
-func (s *natureStore) GetSummaryByYear(year string) (int64, float64, error) {
+func (s *natureStore) GetSummaryByYear(req model.OverviewQueryRequest) (int64, float64, error) {
 	var result struct {
 		TotalCount int64
 		TotalArea  float64
 	}
 
 	// SQL: SELECT count(*) as total_count, sum(BHMJ) as total_area FROM nature_data WHERE year = ?
-	err := s.db.Model(&model.NatureData{}).
-		Select("count(*) as total_count, sum(BHMJ) as total_area").
-		Where("year = ?", year).
-		Scan(&result).Error
+	query := s.db.Model(&model.NatureData{}).
+		Select("count(*) as total_count, COALESCE(sum(BHMJ), 0) as total_area").
+		Where("year = ?", req.Year)
+	if req.ProtectedType != "" {
+		query = query.Where("BHDLX = ?", req.ProtectedType)
+	}
+	if req.Province != "" {
+		query = query.Where("THSHENG = ?", req.Province)
+	}
+	query = applyRegionScope(query, req.UserScope)
+	err := query.Scan(&result).Error
 
 	return result.TotalCount, result.TotalArea, err
 }
 
-// GetDamageStatsByBatch 2. 获取某年“资源损毁”的分批次统计
-func (s *natureStore) GetDamageStatsByBatch(year string) ([]model.BatchStatResult, error) {
-	var results []model.BatchStatResult
-
-	// SQL: SELECT PC, count(*), sum(BHMJ) FROM nature_data WHERE year = ? AND BHDL = '资源损毁' GROUP BY PC
-	err := s.db.Model(&model.NatureData{}).
-		Select("PC, count(*) as count, sum(BHMJ) as area").
-		Where("year = ? AND BHDL = ?", year, "资源损毁").
-		Group("PC").
-		Scan(&results).Error
-
-	return results, err
-}
-
-func (s *natureStore) GetRegionStats(year string, groupCol string, filterCol string, filterVal string) ([]model.RegionStatResult, error) {
+func (s *natureStore) GetRegionStats(year string, groupCol string, filterCol string, filterVal string, scope model.RegionScope) ([]model.RegionStatResult, error) {
 	var results []model.RegionStatResult
 
 	// 构建基础查询
@@ -92,6 +171,7 @@ func (s *natureStore) GetRegionStats(year string, groupCol string, filterCol str
 	if filterCol != "" && filterVal != "" {
 		tx = tx.Where(filterCol+" = ?", filterVal)
 	}
+	tx = applyRegionScope(tx, scope)
 
 	// 执行分组和查询
 	err := tx.Group(groupCol).Scan(&results).Error
@@ -99,9 +179,80 @@ func (s *natureStore) GetRegionStats(year string, groupCol string, filterCol str
 	return results, err
 }
 
+// regionRowAliases 行政区列名 -> RegionRow 的字段
+var regionRowAliases = map[string]string{
+	"THSHENG": "province",
+	"THSHI":   "city",
+	"THXIAN":  "county",
+}
+
+// regionSelect 生成 "THSHENG as province, THSHI as city" 形式的列
+func regionSelect(groupCols []string) string {
+	cols := make([]string, len(groupCols))
+	for i, col := range groupCols {
+		cols[i] = col + " as " + regionRowAliases[col]
+	}
+	return strings.Join(cols, ", ")
+}
+
+func (s *natureStore) GetRegionTreeStats(req model.RegionTreeRequest, groupCols []string) ([]model.RegionRow, error) {
+	var results []model.RegionRow
+
+	query := s.db.Model(&model.NatureData{}).Where("year = ?", req.Year)
+	if req.Province != "" {
+		query = query.Where("THSHENG = ?", req.Province)
+	}
+	if req.ChangeType != "" {
+		query = query.Where("BHDL = ?", req.ChangeType)
+	}
+	if req.ProtectedType != "" {
+		query = query.Where("BHDLX = ?", req.ProtectedType)
+	}
+	query = applyRegionScope(query, req.UserScope)
+
+	group := strings.Join(groupCols, ", ")
+	err := query.Select(regionSelect(groupCols) + ", count(*) as count, COALESCE(sum(BHMJ), 0) as area").
+		Group(group).
+		Order(group).
+		Scan(&results).Error
+
+	return results, err
+}
+
+func (s *natureStore) GetRegionNames(year string, groupCols []string, scope model.RegionScope) ([]model.RegionRow, error) {
+	var results []model.RegionRow
+
+	query := s.db.Model(&model.NatureData{})
+	if year != "" {
+		query = query.Where("year = ?", year)
+	}
+	query = applyRegionScope(query, scope)
+
+	err := query.Select(regionSelect(groupCols)).Distinct().
+		Order(strings.Join(groupCols, ", ")).
+		Scan(&results).Error
+
+	return results, err
+}
+
+// applyRegionScope 追加当前用户的数据范围限制 (省/市/县)，范围为空时不做限制
+func applyRegionScope(tx *gorm.DB, scope model.RegionScope) *gorm.DB {
+	if scope.Province != "" {
+		tx = tx.Where("THSHENG = ?", scope.Province)
+	}
+	if scope.City != "" {
+		tx = tx.Where("THSHI = ?", scope.City)
+	}
+	if scope.County != "" {
+		tx = tx.Where("THXIAN = ?", scope.County)
+	}
+	return tx
+}
+
 // buildCommonQuery 构建公共的筛选条件
 func (s *natureStore) buildCommonQuery(req model.NatureQueryRequest) *gorm.DB {
 	tx := s.db.Model(&model.NatureData{}).Where("year = ?", req.Year)
+	tx = applyRegionScope(tx, req.UserScope)
 
 	// 动态处理行政区范围
 	if req.RegionName != "" {
@@ -125,6 +276,14 @@ func (s *natureStore) buildCommonQuery(req model.NatureQueryRequest) *gorm.DB {
 	return tx
 }
 
+// applyBBox 追加经纬度范围筛选
+func applyBBox(tx *gorm.DB, bbox *model.BBox) *gorm.DB {
+	if bbox == nil {
+		return tx
+	}
+	return tx.Where("X BETWEEN ? AND ? AND Y BETWEEN ? AND ?", bbox.MinX, bbox.MaxX, bbox.MinY, bbox.MaxY)
+}
+
 // GetProtectedAreaStats 接口1: 按保护地分组统计 (带分页)
 func (s *natureStore) GetProtectedAreaStats(req model.NatureQueryRequest) ([]model.ProtectedAreaStat, int64, error) {
 	var results []model.ProtectedAreaStat
@@ -140,11 +299,13 @@ func (s *natureStore) GetProtectedAreaStats(req model.NatureQueryRequest) ([]mod
 	}
 
 	// 3. 执行分组查询 + 分页
-	offset := (req.Page - 1) * req.PageSize
-	err := query.Select("THBHDMC as name, count(*) as count, sum(BHMJ) as area").
+	query = query.Select("THBHDMC as name, count(*) as count, sum(BHMJ) as area").
 		Group("THBHDMC").
-		Limit(req.PageSize).Offset(offset).
-		Scan(&results).Error
+		Order(orderOr(req.OrderBy, "count DESC, name"))
+	if req.PageSize > 0 {
+		query = query.Limit(req.PageSize).Offset((req.Page - 1) * req.PageSize)
+	}
+	err := query.Scan(&results).Error
 
 	return results, total, err
 }
@@ -164,12 +325,90 @@ func (s *natureStore) GetSpotList(req model.NatureQueryRequest) ([]model.SpotLis
 
 	// GORM 会自动把查询到的字段映射到 SpotListItem 的同名字段上
 	err := query.Select("TBBH, QLX, HLX, BHDL").
+		Order(orderOr(req.OrderBy, "TBBH")).
 		Limit(req.PageSize).Offset(offset).
 		Scan(&results).Error
 
 	return results, total, err
 }
 
+func (s *natureStore) GetSpotListColumns(req model.NatureQueryRequest, columns []string) ([]model.NatureData, int64, error) {
+	var results []model.NatureData
+	var total int64
+
+	query := s.buildCommonQuery(req)
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (req.Page - 1) * req.PageSize
+	err := query.Select(columns).
+		Order(orderOr(req.OrderBy, "TBBH")).
+		Limit(req.PageSize).Offset(offset).
+		Find(&results).Error
+
+	return results, total, err
+}
+
+func (s *natureStore) GetSpotListKeyset(req model.NatureQueryRequest, columns []string, k model.Keyset) ([]model.NatureData, error) {
+	var results []model.NatureData
+	err := applyKeyset(s.buildCommonQuery(req).Select(columns), k).Find(&results).Error
+	return results, err
+}
+
+func (s *natureStore) CountSpots(req model.NatureQueryRequest) (int64, error) {
+	var total int64
+	err := s.buildCommonQuery(req).Count(&total).Error
+	return total, err
+}
+
+// applyKeyset 追加游标条件、排序和条数限制
+// 排序为 (Column, TBBH)，"之后" 即 Column 更大 (降序时更小)，或 Column 相同且 TBBH 更大
+// 数值列按 COALESCE(Column, 0) 排序和比较: NULL 扫描到结构体中为 0，游标值与排序值必须一致，否则会跳过 NULL 行
+func applyKeyset(tx *gorm.DB, k model.Keyset) *gorm.DB {
+	dir, op := "", ">"
+	if k.Desc {
+		dir, op = " DESC", "<"
+	}
+
+	if k.Column == "TBBH" {
+		if k.After != nil {
+			tx = tx.Where("TBBH "+op+" ?", k.After.TBBH)
+		}
+		return tx.Order("TBBH" + dir).Limit(k.Limit)
+	}
+
+	col := "COALESCE(" + k.Column + ", 0)"
+	if k.After != nil {
+		tx = tx.Where("("+col+" "+op+" ? OR ("+col+" = ? AND TBBH > ?))",
+			k.After.Value, k.After.Value, k.After.TBBH)
+	}
+	return tx.Order(col + dir + ", TBBH").Limit(k.Limit)
+}
+
+func (s *natureStore) GetRanking(req model.NatureQueryRequest, groupCol, orderBy string, limit int) ([]model.RankingItem, error) {
+	var results []model.RankingItem
+
+	err := s.buildCommonQuery(req).
+		Where(groupCol + " <> ''").
+		Select(groupCol + " as name, count(*) as count, COALESCE(sum(BHMJ), 0) as area").
+		Group(groupCol).
+		Order(orderBy).
+		Limit(limit).
+		Scan(&results).Error
+
+	return results, err
+}
+
+// orderOr 返回已校验的排序子句，为空时使用默认排序
+func orderOr(orderBy, defaultOrder string) string {
+	if orderBy != "" {
+		return orderBy
+	}
+	return defaultOrder
+}
+
 // GetTransitionStats 接口3: 前地类 -> 后地类 流向统计 (不分页，计算占比)
 func (s *natureStore) GetTransitionStats(req model.NatureQueryRequest) ([]model.TransitionStat, error) {
 	var results []model.TransitionStat
@@ -189,14 +428,37 @@ func (s *natureStore) GetTransitionStats(req model.NatureQueryRequest) ([]model.
 	return results, err
 }
 
+// GetTransitionMatrix 前地类 × 后地类 全量流转统计
+func (s *natureStore) GetTransitionMatrix(req model.NatureQueryRequest) ([]model.TransitionPairStat, error) {
+	var results []model.TransitionPairStat
+
+	query := s.buildCommonQuery(req)
+	switch req.QLX {
+	case "":
+	case model.UnknownLandClass:
+		query = query.Where("COALESCE(QLX, '') = ''")
+	default:
+		query = query.Where("QLX = ?", req.QLX)
+	}
+
+	// 空字符串和 NULL 统一归为 "未知" 后再分组，避免同一个 "未知" 出现多行
+	spots := query.Select("COALESCE(NULLIF(QLX, ''), ?) AS qlx, COALESCE(NULLIF(HLX, ''), ?) AS hlx, BHMJ AS bhmj",
+		model.UnknownLandClass, model.UnknownLandClass)
+	err := s.db.Table("(?) AS t", spots).
+		Select("qlx, hlx, count(*) as count, COALESCE(sum(bhmj), 0) as area").
+		Group("qlx, hlx").
+		Order("qlx, hlx").
+		Scan(&results).Error
+
+	return results, err
+}
+
 func (s *natureStore) GetLargeSpots(req model.AlertQueryRequest) ([]model.AlertSpotItem, int64, error) {
 	var results []model.AlertSpotItem
 	var total int64
 
 	// 1. 构建基础查询
-	// 筛选条件: 年份匹配 AND 面积 > 阈值
-	query := s.db.Model(&model.NatureData{}).
-		Where("year = ? AND BHMJ > ?", req.Year, req.AlertArea)
+	query := s.largeSpotsQuery(req)
 
 	// 2. 计算总数 (用于分页)
 	if err := query.Count(&total).Error; err != nil {
@@ -206,9 +468,345 @@ func (s *natureStore) GetLargeSpots(req model.AlertQueryRequest) ([]model.AlertS
 	// 3. 查询列表 (指定字段映射到 DTO)
 	offset := (req.Page - 1) * req.PageSize
 	err := query.Select("THBHDMC, TBBH, BHMJ, THSHENG").
-		Order("BHMJ DESC").
+		Order(orderOr(req.OrderBy, "BHMJ DESC, TBBH")).
+		Limit(req.PageSize).Offset(offset).
+		Scan(&results).Error
+
+	return results, total, err
+}
+
+func (s *natureStore) GetLargeSpotsKeyset(req model.AlertQueryRequest, k model.Keyset) ([]model.AlertSpotItem, error) {
+	var results []model.AlertSpotItem
+	err := applyKeyset(s.largeSpotsQuery(req).Select("THBHDMC, TBBH, BHMJ, THSHENG"), k).
+		Scan(&results).Error
+	return results, err
+}
+
+func (s *natureStore) CountLargeSpots(req model.AlertQueryRequest) (int64, error) {
+	var total int64
+	err := s.largeSpotsQuery(req).Count(&total).Error
+	return total, err
+}
+
+func (s *natureStore) GetSpot(tbbh string, scope model.RegionScope) (*model.NatureData, error) {
+	var spot model.NatureData
+	err := applyRegionScope(s.db.Where("TBBH = ?", tbbh), scope).First(&spot).Error
+	if err != nil {
+		return nil, err
+	}
+	return &spot, nil
+}
+
+// inQueryChunk IN 查询每次携带的最大参数个数
+const inQueryChunk = 1000
+
+func (s *natureStore) GetSpotsByTBBH(tbbhs []string, scope model.RegionScope) ([]model.NatureData, error) {
+	return s.findSpotsIn("TBBH", tbbhs, scope)
+}
+
+func (s *natureStore) GetSuccessorSpots(tbbhs []string, scope model.RegionScope) ([]model.NatureData, error) {
+	return s.findSpotsIn("SQTBBH", tbbhs, scope)
+}
+
+func (s *natureStore) ListSpotKeys() ([]model.NatureData, error) {
+	var results []model.NatureData
+	err := s.db.Select("TBBH", "year", "PC", "THSHENG").Order("TBBH").Find(&results).Error
+	return results, err
+}
+
+// findSpotsIn 按 column IN (values) 分批查询，结果按 TBBH 排序
+func (s *natureStore) findSpotsIn(column string, values []string, scope model.RegionScope) ([]model.NatureData, error) {
+	var results []model.NatureData
+	for start := 0; start < len(values); start += inQueryChunk {
+		end := min(start+inQueryChunk, len(values))
+
+		var chunk []model.NatureData
+		err := applyRegionScope(s.db.Where(column+" IN ?", values[start:end]), scope).
+			Order("TBBH").
+			Find(&chunk).Error
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, chunk...)
+	}
+	return results, nil
+}
+
+func (s *natureStore) GetRepeatChangeStats(req model.NatureQueryRequest) ([]model.RepeatChangeTransitionStat, error) {
+	var results []model.RepeatChangeTransitionStat
+
+	// 1. 每个重复变化图斑带上上期变化地类 (相关子查询，上期图斑同样受数据范围限制)
+	prevSQL := "SELECT p.BHDL FROM nature_data AS p WHERE p.TBBH = nature_data.SQTBBH"
+	var prevArgs []interface{}
+	for _, cond := range []struct{ col, val string }{
+		{"THSHENG", req.UserScope.Province},
+		{"THSHI", req.UserScope.City},
+		{"THXIAN", req.UserScope.County},
+	} {
+		if cond.val != "" {
+			prevSQL += " AND p." + cond.col + " = ?"
+			prevArgs = append(prevArgs, cond.val)
+		}
+	}
+	spots := s.buildCommonQuery(req).
+		Where("SFCXBH = ?", 1).
+		Select("THBHDMC AS name, BHDL AS bhdl, BHMJ AS bhmj, COALESCE(("+prevSQL+" LIMIT 1), '') AS prev_bhdl", prevArgs...)
+
+	// 2. 再按 保护地、上期地类、本期地类 聚合
+	err := s.db.Table("(?) AS t", spots).
+		Select("name, prev_bhdl, bhdl, COUNT(*) AS count, COALESCE(SUM(bhmj), 0) AS area").
+		Group("name, prev_bhdl, bhdl").
+		Order("name, prev_bhdl, bhdl").
+		Scan(&results).Error
+	return results, err
+}
+
+func (s *natureStore) GetRepeatChangeSpots(req model.NatureQueryRequest, name string) ([]model.NatureData, int64, error) {
+	var results []model.NatureData
+	var total int64
+
+	query := s.buildCommonQuery(req).Where("SFCXBH = ?", 1)
+	if name != "" {
+		query = query.Where("THBHDMC = ?", name)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (req.Page - 1) * req.PageSize
+	err := query.Order(orderOr(req.OrderBy, "TBBH")).
+		Limit(req.PageSize).Offset(offset).
+		Find(&results).Error
+
+	return results, total, err
+}
+
+// attributionDiffCond 字段不一致的条件: 原报值非空且与核实值不同 (原报为空视为未填报，不算差异)
+// 字段配置了 YBValues 时原报值先按映射表转换，表中没有的值保持原样比较
+func attributionDiffCond(f model.AttributionField) string {
+	yb := fmt.Sprintf("COALESCE(%s, '')", f.YB)
+	if len(f.YBValues) > 0 {
+		keys := make([]string, 0, len(f.YBValues))
+		for k := range f.YBValues {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		var b strings.Builder
+		b.WriteString("CASE " + yb)
+		for _, k := range keys {
+			fmt.Fprintf(&b, " WHEN %s THEN %s", sqlQuote(k), sqlQuote(f.YBValues[k]))
+		}
+		b.WriteString(" ELSE " + yb + " END")
+		yb = b.String()
+	}
+	return fmt.Sprintf("(%s <> '' AND %s <> COALESCE(%s, ''))", yb, yb, f.TH)
+}
+
+// sqlQuote 把代码中的常量写成 SQL 字符串字面量 (只用于固定的映射表，不能用于用户输入)
+func sqlQuote(s string) string {
+	return "'" + strings.ReplaceAll(strings.ReplaceAll(s, `\`, `\\`), "'", "''") + "'"
+}
+
+// attributionAnyDiffCond fields 中任一字段不一致
+func attributionAnyDiffCond(fields []model.AttributionField) string {
+	conds := make([]string, len(fields))
+	for i, f := range fields {
+		conds[i] = attributionDiffCond(f)
+	}
+	return "(" + strings.Join(conds, " OR ") + ")"
+}
+
+func (s *natureStore) GetAttributionDiffStats(req model.NatureQueryRequest, groupCol string) ([]model.AttributionDiffStat, error) {
+	var results []model.AttributionDiffStat
+
+	// SELECT THSHENG AS name, count(*) AS total,
+	//        SUM(CASE WHEN (任一字段不一致) THEN 1 ELSE 0 END) AS mismatched,
+	//        SUM(CASE WHEN (YBSHENG 与 THSHENG 不一致) THEN 1 ELSE 0 END) AS province_diff, ...
+	columns := []string{
+		groupCol + " AS name",
+		"count(*) AS total",
+		"SUM(CASE WHEN " + attributionAnyDiffCond(model.AttributionFields) + " THEN 1 ELSE 0 END) AS mismatched",
+	}
+	for _, f := range model.AttributionFields {
+		columns = append(columns, "SUM(CASE WHEN "+attributionDiffCond(f)+" THEN 1 ELSE 0 END) AS "+f.Key+"_diff")
+	}
+
+	err := s.buildCommonQuery(req).
+		Select(strings.Join(columns, ", ")).
+		Group(groupCol).
+		Order("mismatched DESC, name").
+		Scan(&results).Error
+
+	return results, err
+}
+
+func (s *natureStore) GetAttributionDiffSpots(req model.NatureQueryRequest, fields []model.AttributionField) ([]model.AttributionDiffSpot, int64, error) {
+	var results []model.AttributionDiffSpot
+	var total int64
+
+	query := s.buildCommonQuery(req).Where(attributionAnyDiffCond(fields))
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (req.Page - 1) * req.PageSize
+	// CONCAT_WS 会跳过 NULL，结果为不一致字段的 Key 列表，例如 province,county
+	diffCols := make([]string, len(fields))
+	for i, f := range fields {
+		diffCols[i] = fmt.Sprintf("CASE WHEN %s THEN '%s' END", attributionDiffCond(f), f.Key)
+	}
+	err := query.Select("nature_data.*, CONCAT_WS(',', " + strings.Join(diffCols, ", ") + ") AS diff_fields").
+		Order(orderOr(req.OrderBy, "TBBH")).
+		Limit(req.PageSize).Offset(offset).
+		Scan(&results).Error
+
+	return results, total, err
+}
+
+// StreamLargeSpots 与 GetLargeSpots 条件相同，但不分页，逐行读取
+func (s *natureStore) StreamLargeSpots(req model.AlertQueryRequest, fn func(*model.AlertSpotItem) error) error {
+	rows, err := s.largeSpotsQuery(req).
+		Select("THBHDMC, TBBH, BHMJ, THSHENG").
+		Order(orderOr(req.OrderBy, "BHMJ DESC, TBBH")).
+		Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item model.AlertSpotItem
+		if err := s.db.ScanRows(rows, &item); err != nil {
+			return err
+		}
+		if err := fn(&item); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// largeSpotsQuery 预警图斑的筛选条件: 年份匹配 AND 面积 > 阈值
+func (s *natureStore) largeSpotsQuery(req model.AlertQueryRequest) *gorm.DB {
+	query := s.db.Model(&model.NatureData{}).
+		Where("year = ? AND BHMJ > ?", req.Year, req.AlertArea)
+	return applyRegionScope(query, req.UserScope)
+}
+
+// spotLocationColumns 空间查询返回的列
+const spotLocationColumns = "TBBH, BHDL, QLX, HLX, X, Y, BHMJ, THBHDMC"
+
+// GetSpotsInBBox 范围查询: 复用公共筛选条件，再追加经纬度范围
+func (s *natureStore) GetSpotsInBBox(req model.NatureQueryRequest, bbox model.BBox) ([]model.SpotLocationItem, int64, error) {
+	var results []model.SpotLocationItem
+	var total int64
+
+	query := applyBBox(s.buildCommonQuery(req), &bbox)
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (req.Page - 1) * req.PageSize
+	err := query.Select(spotLocationColumns).
+		Order(orderOr(req.OrderBy, "TBBH")).
+		Limit(req.PageSize).Offset(offset).
+		Scan(&results).Error
+
+	return results, total, err
+}
+
+// GetSpotsWithinRadius 半径查询: 先用外接矩形粗筛 (可以利用 X/Y 上的索引)，再用 Haversine 公式精确过滤
+func (s *natureStore) GetSpotsWithinRadius(req model.NatureQueryRequest, lng, lat, radius float64) ([]model.SpotLocationItem, int64, error) {
+	var results []model.SpotLocationItem
+	var total int64
+
+	// Haversine 大圆距离 (米)，参数依次为: 纬度, 纬度, 经度
+	distanceExpr := "2 * ? * ASIN(SQRT(POWER(SIN(RADIANS(Y - ?) / 2), 2) + " +
+		"COS(RADIANS(?)) * COS(RADIANS(Y)) * POWER(SIN(RADIANS(X - ?) / 2), 2)))"
+	distanceArgs := []interface{}{model.EarthRadiusMeters, lat, lat, lng}
+
+	bbox := model.RadiusBBox(lng, lat, radius)
+	query := applyBBox(s.buildCommonQuery(req), &bbox).
+		Where(distanceExpr+" <= ?", append(distanceArgs, radius)...)
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (req.Page - 1) * req.PageSize
+	err := query.Select(spotLocationColumns+", "+distanceExpr+" AS distance", distanceArgs...).
+		Order(orderOr(req.OrderBy, "distance, TBBH")).
 		Limit(req.PageSize).Offset(offset).
 		Scan(&results).Error
 
 	return results, total, err
 }
+
+// GetSpotClusters 网格聚合: 以 bbox 左下角为原点，按 FLOOR((X - minx) / cellSize) 分组
+func (s *natureStore) GetSpotClusters(req model.NatureQueryRequest, bbox model.BBox, cellSize float64) ([]model.SpotCluster, error) {
+	var results []model.SpotCluster
+
+	err := applyBBox(s.buildCommonQuery(req), &bbox).
+		Select("FLOOR((X - ?) / ?) AS cell_x, FLOOR((Y - ?) / ?) AS cell_y, "+
+			"count(*) AS count, COALESCE(sum(BHMJ), 0) AS area, avg(X) AS x, avg(Y) AS y, "+
+			"CASE WHEN count(*) = 1 THEN MIN(TBBH) ELSE '' END AS tbbh",
+			bbox.MinX, cellSize, bbox.MinY, cellSize).
+		Group("cell_x, cell_y").
+		Scan(&results).Error
+
+	return results, err
+}
+
+// StreamSpots 使用游标逐行读取，避免一次性把大量图斑加载到内存
+func (s *natureStore) StreamSpots(q model.SpotStreamQuery, fn func(*model.NatureData) error) error {
+	query := applyBBox(s.buildCommonQuery(q.NatureQueryRequest), q.BBox)
+	if q.RequireLocation {
+		query = query.Where("NOT (X = 0 AND Y = 0)")
+	}
+	query = query.Order(orderOr(q.OrderBy, "TBBH"))
+	if q.Limit > 0 {
+		query = query.Limit(q.Limit)
+	}
+
+	rows, err := query.Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var spot model.NatureData
+		if err := s.db.ScanRows(rows, &spot); err != nil {
+			return err
+		}
+		if err := fn(&spot); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// UpsertSpots 按 TBBH 插入或更新 (INSERT ... ON DUPLICATE KEY UPDATE 只更新 columns)
+func (s *natureStore) UpsertSpots(spots []model.NatureData, columns []string) error {
+	if len(spots) == 0 {
+		return nil
+	}
+	// 主键不需要更新；没有其它列时重复的图斑保持不变
+	var updates []string
+	for _, col := range columns {
+		if col != "TBBH" {
+			updates = append(updates, col)
+		}
+	}
+	onConflict := clause.OnConflict{Columns: []clause.Column{{Name: "TBBH"}}, DoNothing: true}
+	if len(updates) > 0 {
+		onConflict = clause.OnConflict{Columns: []clause.Column{{Name: "TBBH"}}, DoUpdates: clause.AssignmentColumns(updates)}
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		return tx.Clauses(onConflict).CreateInBatches(spots, 200).Error
+	})
+}
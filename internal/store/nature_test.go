@@ -0,0 +1,117 @@
+package store
+
+import (
+	"ProtectedArea/internal/model"
+	"strings"
+	"testing"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func TestAttributionDiffCond(t *testing.T) {
+	tests := []struct {
+		name  string
+		field model.AttributionField
+		want  []string // 生成的条件应包含的片段
+	}{
+		{
+			name:  "直接比较",
+			field: model.AttributionField{Key: "province", TH: "THSHENG", YB: "YBSHENG"},
+			want:  []string{"(COALESCE(YBSHENG, '') <> '' AND COALESCE(YBSHENG, '') <> COALESCE(THSHENG, ''))"},
+		},
+		{
+			name:  "原报值先按映射表转换",
+			field: model.AttributionField{Key: "protected_type", TH: "BHDLX", YB: "YBBHDLXBM", YBValues: map[string]string{"国家公园": "NP", "NP": "NP"}},
+			want: []string{
+				"CASE COALESCE(YBBHDLXBM, '') WHEN 'NP' THEN 'NP' WHEN '国家公园' THEN 'NP' ELSE COALESCE(YBBHDLXBM, '') END <> ''",
+				"END <> COALESCE(BHDLX, ''))",
+			},
+		},
+		{
+			name:  "映射值中的引号被转义",
+			field: model.AttributionField{Key: "x", TH: "A", YB: "B", YBValues: map[string]string{"it's": `a\b`}},
+			want:  []string{`WHEN 'it''s' THEN 'a\\b'`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := attributionDiffCond(tt.field)
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("attributionDiffCond = %s\nwant containing %s", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestAttributionFieldsProtectedType(t *testing.T) {
+	// 保护地类型的原报值可能是中文名，必须配置映射表，否则同一类型也会被当作差异
+	for _, f := range model.AttributionFields {
+		if f.Key != "protected_type" {
+			continue
+		}
+		for name, abbr := range map[string]string{"国家级自然保护区": "NR", "国家公园": "NP", "NR": "NR"} {
+			if f.YBValues[name] != abbr {
+				t.Errorf("YBValues[%q] = %q, want %q", name, f.YBValues[name], abbr)
+			}
+		}
+		return
+	}
+	t.Fatal("protected_type not found in AttributionFields")
+}
+
+// dryRunDB 只生成 SQL 不连接数据库
+func dryRunDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(mysql.New(mysql.Config{DSN: "user:pass@tcp(127.0.0.1:3306)/test", SkipInitializeWithVersion: true}),
+		&gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestApplyKeyset(t *testing.T) {
+	db := dryRunDB(t)
+
+	tests := []struct {
+		name string
+		k    model.Keyset
+		want string
+	}{
+		{
+			name: "按 TBBH 第一页",
+			k:    model.Keyset{Column: "TBBH", Limit: 11},
+			want: "SELECT * FROM `nature_data` ORDER BY TBBH LIMIT 11",
+		},
+		{
+			name: "按 TBBH 降序",
+			k:    model.Keyset{Column: "TBBH", Desc: true, After: &model.Cursor{TBBH: "A9"}, Limit: 11},
+			want: "SELECT * FROM `nature_data` WHERE TBBH < 'A9' ORDER BY TBBH DESC LIMIT 11",
+		},
+		{
+			// NULL 面积按 0 参与比较和排序，游标值为 0 时不会跳过 NULL 行
+			name: "按面积升序，游标位于 NULL 面积",
+			k:    model.Keyset{Column: "BHMJ", After: &model.Cursor{Value: 0, TBBH: "A1"}, Limit: 11},
+			want: "SELECT * FROM `nature_data` WHERE (COALESCE(BHMJ, 0) > 0 OR (COALESCE(BHMJ, 0) = 0 AND TBBH > 'A1')) ORDER BY COALESCE(BHMJ, 0), TBBH LIMIT 11",
+		},
+		{
+			name: "按面积降序",
+			k:    model.Keyset{Column: "BHMJ", Desc: true, After: &model.Cursor{Value: 2.5, TBBH: "A1"}, Limit: 11},
+			want: "SELECT * FROM `nature_data` WHERE (COALESCE(BHMJ, 0) < 2.5 OR (COALESCE(BHMJ, 0) = 2.5 AND TBBH > 'A1')) ORDER BY COALESCE(BHMJ, 0) DESC, TBBH LIMIT 11",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+				var spots []model.NatureData
+				return applyKeyset(tx.Model(&model.NatureData{}), tt.k).Find(&spots)
+			})
+			if got != tt.want {
+				t.Errorf("SQL = %s\nwant  %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package store
+
+import (
+	"ProtectedArea/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// ProtectedAreaStore 保护地名录的数据访问接口
+type ProtectedAreaStore interface {
+	List(req model.ProtectedAreaQueryRequest) ([]model.ProtectedArea, int64, error)
+	GetByID(id uint) (*model.ProtectedArea, error)
+	Create(pa *model.ProtectedArea) error
+	Update(pa *model.ProtectedArea) error
+	Delete(id uint) error
+
+	// GetSummary 统计名录中保护地个数和批复总面积，typeCode/province 为空表示不筛选
+	GetSummary(typeCode, province string) (int64, float64, error)
+}
+
+type protectedAreaStore struct {
+	db *gorm.DB
+}
+
+// NewProtectedAreaStore 构造函数
+func NewProtectedAreaStore(db *gorm.DB) ProtectedAreaStore {
+	return &protectedAreaStore{db: db}
+}
+
+// List 分页查询保护地名录
+func (s *protectedAreaStore) List(req model.ProtectedAreaQueryRequest) ([]model.ProtectedArea, int64, error) {
+	var results []model.ProtectedArea
+	var total int64
+
+	query := s.db.Model(&model.ProtectedArea{})
+	if req.Name != "" {
+		query = query.Where("name LIKE ?", "%"+req.Name+"%")
+	}
+	if req.TypeCode != "" {
+		query = query.Where("type_code = ?", req.TypeCode)
+	}
+	if req.Level != "" {
+		query = query.Where("level = ?", req.Level)
+	}
+	if req.Province != "" {
+		query = query.Where("province = ?", req.Province)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (req.Page - 1) * req.PageSize
+	err := query.Order("id").
+		Limit(req.PageSize).Offset(offset).
+		Find(&results).Error
+
+	return results, total, err
+}
+
+// GetByID 按主键查询，不存在时返回 gorm.ErrRecordNotFound
+func (s *protectedAreaStore) GetByID(id uint) (*model.ProtectedArea, error) {
+	var pa model.ProtectedArea
+	if err := s.db.First(&pa, id).Error; err != nil {
+		return nil, err
+	}
+	return &pa, nil
+}
+
+func (s *protectedAreaStore) Create(pa *model.ProtectedArea) error {
+	return s.db.Create(pa).Error
+}
+
+// Update 全量更新 (包括零值字段)
+func (s *protectedAreaStore) Update(pa *model.ProtectedArea) error {
+	return s.db.Save(pa).Error
+}
+
+// Delete 删除，不存在时返回 gorm.ErrRecordNotFound
+func (s *protectedAreaStore) Delete(id uint) error {
+	result := s.db.Delete(&model.ProtectedArea{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// GetSummary 统计保护地个数和批复总面积
+func (s *protectedAreaStore) GetSummary(typeCode, province string) (int64, float64, error) {
+	var result struct {
+		TotalCount int64
+		TotalArea  float64
+	}
+
+	// SQL: SELECT count(*) as total_count, sum(official_area) as total_area FROM protected_area WHERE ...
+	query := s.db.Model(&model.ProtectedArea{}).
+		Select("count(*) as total_count, COALESCE(sum(official_area), 0) as total_area")
+	if typeCode != "" {
+		query = query.Where("type_code = ?", typeCode)
+	}
+	if province != "" {
+		query = query.Where("province = ?", province)
+	}
+	err := query.Scan(&result).Error
+
+	return result.TotalCount, result.TotalArea, err
+}
@@ -0,0 +1,84 @@
+package store
+
+import (
+	"ProtectedArea/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// UserStore 用户表的数据访问接口
+type UserStore interface {
+	List() ([]model.User, error)
+	Count() (int64, error)
+	GetByID(id uint) (*model.User, error)
+	GetByUsername(username string) (*model.User, error)
+	GetByAPIKeyHash(hash string) (*model.User, error)
+	Create(u *model.User) error
+	Update(u *model.User) error
+	Delete(id uint) error
+}
+
+type userStore struct {
+	db *gorm.DB
+}
+
+// NewUserStore 构造函数
+func NewUserStore(db *gorm.DB) UserStore {
+	return &userStore{db: db}
+}
+
+func (s *userStore) List() ([]model.User, error) {
+	var users []model.User
+	err := s.db.Order("id").Find(&users).Error
+	return users, err
+}
+
+func (s *userStore) Count() (int64, error) {
+	var total int64
+	err := s.db.Model(&model.User{}).Count(&total).Error
+	return total, err
+}
+
+// GetByID 不存在时返回 gorm.ErrRecordNotFound，下同
+func (s *userStore) GetByID(id uint) (*model.User, error) {
+	var u model.User
+	if err := s.db.First(&u, id).Error; err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+func (s *userStore) GetByUsername(username string) (*model.User, error) {
+	var u model.User
+	if err := s.db.Where("username = ?", username).First(&u).Error; err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+func (s *userStore) GetByAPIKeyHash(hash string) (*model.User, error) {
+	var u model.User
+	if err := s.db.Where("api_key_hash = ?", hash).First(&u).Error; err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+func (s *userStore) Create(u *model.User) error {
+	return s.db.Create(u).Error
+}
+
+func (s *userStore) Update(u *model.User) error {
+	return s.db.Save(u).Error
+}
+
+func (s *userStore) Delete(id uint) error {
+	result := s.db.Delete(&model.User{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
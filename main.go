@@ -1,36 +1,132 @@
 package main
 
 import (
+	"ProtectedArea/internal/config"
 	"ProtectedArea/internal/handler"
+	"ProtectedArea/internal/middleware"
 	"ProtectedArea/internal/router"
 	"ProtectedArea/internal/service"
 	"ProtectedArea/internal/store"
+	"flag"
+	"fmt"
 	"log"
+	"os"
+	"strings"
 
-	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 )
 
+const usage = `用法:
+  ProtectedArea [serve] [-config path]        启动 HTTP 服务 (默认)
+  ProtectedArea import -file path [选项]      从 CSV/XLSX 批量导入图斑
+  ProtectedArea check-images [选项]           检查图片目录 (缺图/孤立文件/损坏/重复)
+
+使用 "ProtectedArea <命令> -h" 查看命令的详细参数
+`
+
 func main() {
-	// 1. 初始化数据库连接
-	dsn := "root:123456@tcp(127.0.0.1:3306)/protected_area?charset=utf8mb4&parseTime=True&loc=Local"
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
-	if err != nil {
-		log.Fatal("数据库连接失败:", err)
+	// 第一个参数不是 "-xxx" 时视为子命令
+	cmd, args := "serve", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		cmd, args = args[0], args[1:]
+	}
+
+	switch cmd {
+	case "serve":
+		runServe(args)
+	case "import":
+		runImport(args)
+	case "check-images":
+		runCheckImages(args)
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
 	}
+}
+
+// runServe 启动 HTTP 服务
+func runServe(args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	configPath := fs.String("config", "", "配置文件路径 (默认读取 PA_CONFIG 或 "+config.DefaultPath+")")
+	_ = fs.Parse(args)
+
+	// 0. 加载配置 (YAML + 环境变量)，并初始化数据库连接
+	cfg, db := mustSetup(*configPath)
 
 	// 2. 依赖注入 (层层组装)
 	// Store 依赖 DB
 	natureStore := store.NewNatureStore(db)
+	protectedAreaStore := store.NewProtectedAreaStore(db)
+	userStore := store.NewUserStore(db)
+	batchStore := store.NewBatchStore(db)
+	imageStore := store.NewImageStore(db)
 	// Service 依赖 Store
-	natureService := service.NewNatureService(natureStore)
+	batchService := service.NewBatchService(batchStore)
+	// 批次表为空时写入默认规则，并加载到内存
+	if err := batchService.EnsureDefaults(); err != nil {
+		log.Fatal(err)
+	}
+	natureService := service.NewNatureService(natureStore, protectedAreaStore, imageStore, batchService, cfg.Image.Root)
+	protectedAreaService := service.NewProtectedAreaService(protectedAreaStore)
+	importService := service.NewImportService(natureStore)
+	authService := service.NewAuthService(userStore, cfg.Auth.JWTSecret, cfg.Auth.TokenTTL)
+	userService := service.NewUserService(userStore)
+	imageService := service.NewImageService(imageStore, natureStore, cfg.Image.Root, cfg.Image.ThumbnailDir())
+	// 用户表为空时创建初始管理员
+	if err := authService.EnsureAdmin(cfg.Auth.AdminUsername, cfg.Auth.AdminPassword); err != nil {
+		log.Fatal(err)
+	}
+	// 图片目录可能很大，在后台扫描，不阻塞启动
+	if cfg.Image.ScanOnStart {
+		go func() {
+			result, err := imageService.Rescan()
+			if err != nil {
+				log.Println("扫描图片目录失败: ", err)
+				return
+			}
+			log.Printf("图片索引已更新: 识别 %d 张，新增 %d，更新 %d，删除 %d，跳过 %d (%s)",
+				result.Scanned, result.Added, result.Updated, result.Removed, result.Skipped, result.Duration)
+		}()
+	}
+	if !cfg.Auth.Enabled {
+		log.Println("警告: 认证已关闭 (auth.enabled=false)，所有接口不做权限控制")
+	}
 	// Handler 依赖 Service
-	natureHandler := handler.NewNatureHandler(natureService)
+	handlers := router.Handlers{
+		Nature:        handler.NewNatureHandler(natureService),
+		ProtectedArea: handler.NewProtectedAreaHandler(protectedAreaService),
+		Import:        handler.NewImportHandler(importService),
+		Auth:          handler.NewAuthHandler(authService),
+		User:          handler.NewUserHandler(userService),
+		Batch:         handler.NewBatchHandler(batchService),
+		Image:         handler.NewImageHandler(imageService),
+		Pack:          handler.NewPackHandler(natureService, imageService),
+	}
 
 	// 3. 初始化路由
-	r := router.InitRouter(natureHandler)
+	r := router.InitRouter(cfg.Server.Mode, handlers, middleware.Authenticate(authService, cfg.Auth.Enabled))
 
 	// 4. 启动服务
-	//log.Println("服务启动在 :8080 端口...")
-	r.Run(":9094")
+	log.Printf("服务启动在 %s 端口...", cfg.Server.Addr())
+	if err := r.Run(cfg.Server.Addr()); err != nil {
+		log.Fatal("服务启动失败: ", err)
+	}
+}
+
+// mustSetup 加载配置并连接数据库 (包括表迁移)，失败时直接退出
+func mustSetup(configPath string) (*config.Config, *gorm.DB) {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		log.Fatal("加载配置失败: ", err)
+	}
+
+	// 1. 初始化数据库连接
+	db, err := store.NewDB(cfg.Database)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := store.AutoMigrate(db); err != nil {
+		log.Fatal(err)
+	}
+	return cfg, db
 }
@@ -0,0 +1,120 @@
+// Package errcode 定义统一的错误码目录
+//
+// 错误码规则: HTTP 状态码 * 100 + 序号，例如 40401 表示 404 下的第 1 个具体错误，
+// 序号为 00 的是该类别的通用错误。客户端应根据 code 而不是 message 做分支判断。
+package errcode
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Error 带错误码的业务错误
+type Error struct {
+	Code    int    // 机器可读的错误码
+	Message string // 面向用户的提示信息
+	cause   error  // 原始错误 (只用于日志，不返回给客户端)
+}
+
+// newError 定义目录中的错误码
+func newError(code int, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+func (e *Error) Error() string {
+	if e.cause != nil {
+		return fmt.Sprintf("%d %s: %v", e.Code, e.Message, e.cause)
+	}
+	return fmt.Sprintf("%d %s", e.Code, e.Message)
+}
+
+// Unwrap 支持 errors.Is / errors.As 穿透到原始错误
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// Is 错误码相同即视为同一错误，使 errors.Is(err, errcode.NotFound) 对 WithMessage 派生的错误也成立
+func (e *Error) Is(target error) bool {
+	var t *Error
+	return errors.As(target, &t) && t.Code == e.Code
+}
+
+// HTTPStatus 根据错误码得到 HTTP 状态码
+func (e *Error) HTTPStatus() int {
+	return e.Code / 100
+}
+
+// WithMessage 返回同错误码、不同提示信息的新错误
+func (e *Error) WithMessage(message string) *Error {
+	return &Error{Code: e.Code, Message: message, cause: e.cause}
+}
+
+// WithMessagef 同 WithMessage，支持格式化
+func (e *Error) WithMessagef(format string, args ...interface{}) *Error {
+	return e.WithMessage(fmt.Sprintf(format, args...))
+}
+
+// WithCause 记录原始错误，便于日志排查
+func (e *Error) WithCause(cause error) *Error {
+	return &Error{Code: e.Code, Message: e.Message, cause: cause}
+}
+
+// From 把任意错误转换为 *Error，无法识别的错误一律视为内部错误
+func From(err error) *Error {
+	if err == nil {
+		return nil
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	return Internal.WithCause(err)
+}
+
+// 成功
+const OK = 0
+
+// 参数校验类 (400)
+var (
+	InvalidParams = newError(40000, "请求参数错误")
+)
+
+// 认证与授权类 (401 / 403)
+var (
+	Unauthorized       = newError(40100, "未登录或登录已过期")
+	InvalidCredentials = newError(40101, "用户名或密码错误")
+	Forbidden          = newError(40300, "没有权限访问")
+)
+
+// 资源不存在类 (404)
+var (
+	NotFound              = newError(40400, "资源不存在")
+	ProtectedAreaNotFound = newError(40401, "保护地不存在")
+	SpotNotFound          = newError(40402, "图斑不存在")
+	ImageNotFound         = newError(40403, "暂无图片")
+	UserNotFound          = newError(40404, "用户不存在")
+	BatchNotFound         = newError(40405, "批次不存在")
+)
+
+// 冲突类 (409)
+var (
+	Conflict            = newError(40900, "资源冲突")
+	ProtectedAreaExists = newError(40901, "保护地名称已存在")
+	UserExists          = newError(40902, "用户名已存在")
+	ImageExists         = newError(40903, "图片已存在")
+)
+
+// 请求体过大 (413)
+var (
+	PayloadTooLarge = newError(41300, "上传内容过大")
+)
+
+// 业务规则类 (422): 参数格式正确，但不符合业务规则
+var (
+	BusinessRule = newError(42200, "不符合业务规则")
+)
+
+// 服务器内部错误 (500)
+var (
+	Internal = newError(50000, "服务器内部错误")
+)
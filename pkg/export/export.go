@@ -0,0 +1,155 @@
+// Package export 把表格数据逐行写成 CSV 或 XLSX
+//
+// 调用方先写表头再逐行写数据，最后必须调用 Close (CSV 刷新缓冲区，XLSX 输出整个工作簿)。
+// 以 = + - @ 等开头的字符串单元格会加上 ' 前缀，避免导入数据中的内容在 Excel 中被当作公式执行。
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// 支持的导出格式
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+// sheetName XLSX 导出使用的工作表名
+const sheetName = "Sheet1"
+
+// Writer 表格写入器，单元格可以是字符串或数值
+type Writer interface {
+	WriteRow(cells ...interface{}) error
+	Close() error
+}
+
+// ContentType 返回导出格式对应的 MIME 类型
+func ContentType(format string) string {
+	if format == FormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// IsSupported 判断是否为支持的导出格式
+func IsSupported(format string) bool {
+	return format == FormatCSV || format == FormatXLSX
+}
+
+// NewWriter 根据格式创建写入器
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w)
+	case FormatXLSX:
+		return newXLSXWriter(w)
+	default:
+		return nil, fmt.Errorf("不支持的导出格式: %s", format)
+	}
+}
+
+// csvWriter 直接写入底层 io.Writer，内存占用与行数无关
+type csvWriter struct {
+	buf *bufio.Writer
+	csv *csv.Writer
+}
+
+func newCSVWriter(w io.Writer) (*csvWriter, error) {
+	buf := bufio.NewWriter(w)
+	// 写入 UTF-8 BOM，否则 Excel 打开中文会乱码
+	if _, err := buf.WriteString("\xef\xbb\xbf"); err != nil {
+		return nil, err
+	}
+	return &csvWriter{buf: buf, csv: csv.NewWriter(buf)}, nil
+}
+
+func (w *csvWriter) WriteRow(cells ...interface{}) error {
+	record := make([]string, len(cells))
+	for i, v := range cells {
+		record[i] = formatCell(v)
+	}
+	return w.csv.Write(record)
+}
+
+func (w *csvWriter) Close() error {
+	w.csv.Flush()
+	if err := w.csv.Error(); err != nil {
+		return err
+	}
+	return w.buf.Flush()
+}
+
+// xlsxWriter 使用 excelize 的流式写入，行数较多时数据暂存在临时文件中，
+// Close 时把整个工作簿写到底层 io.Writer
+type xlsxWriter struct {
+	out  io.Writer
+	file *excelize.File
+	sw   *excelize.StreamWriter
+	row  int
+}
+
+func newXLSXWriter(w io.Writer) (*xlsxWriter, error) {
+	f := excelize.NewFile()
+	sw, err := f.NewStreamWriter(sheetName)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &xlsxWriter{out: w, file: f, sw: sw}, nil
+}
+
+func (w *xlsxWriter) WriteRow(cells ...interface{}) error {
+	w.row++
+	cell, err := excelize.CoordinatesToCellName(1, w.row)
+	if err != nil {
+		return err
+	}
+	values := make([]interface{}, len(cells))
+	for i, v := range cells {
+		if s, ok := v.(string); ok {
+			v = escapeFormula(s)
+		}
+		values[i] = v
+	}
+	return w.sw.SetRow(cell, values)
+}
+
+func (w *xlsxWriter) Close() error {
+	defer w.file.Close()
+	if err := w.sw.Flush(); err != nil {
+		return err
+	}
+	return w.file.Write(w.out)
+}
+
+// formatCell 把单元格转换为 CSV 文本，浮点数不使用科学计数法
+func formatCell(v interface{}) string {
+	switch x := v.(type) {
+	case string:
+		return escapeFormula(x)
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(x)
+	}
+}
+
+// formulaPrefixes 电子表格会当作公式处理的首字符
+const formulaPrefixes = "=+-@\t\r"
+
+// escapeFormula 以公式字符开头的字符串前面加上 '，Excel 会把它显示为普通文本
+func escapeFormula(s string) string {
+	if s != "" && strings.ContainsRune(formulaPrefixes, rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
@@ -0,0 +1,98 @@
+package export
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestEscapeFormula(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{in: "", want: ""},
+		{in: "资源损毁", want: "资源损毁"},
+		{in: "110109NR001", want: "110109NR001"},
+		{in: "=1+1", want: "'=1+1"},
+		{in: "+86 010", want: "'+86 010"},
+		{in: "-cmd|' /C calc'!A0", want: "'-cmd|' /C calc'!A0"},
+		{in: "@SUM(A1)", want: "'@SUM(A1)"},
+		{in: "\t=1", want: "'\t=1"},
+		{in: "\r=1", want: "'\r=1"},
+		{in: "a=1", want: "a=1"},
+	}
+	for _, tt := range tests {
+		if got := escapeFormula(tt.in); got != tt.want {
+			t.Errorf("escapeFormula(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFormatCell(t *testing.T) {
+	tests := []struct {
+		in   interface{}
+		want string
+	}{
+		{in: "=HYPERLINK()", want: "'=HYPERLINK()"},
+		{in: 0.00001, want: "0.00001"},
+		{in: 1e21, want: "1000000000000000000000"},
+		// 数值不转义: 负数是正常的取值
+		{in: -1.5, want: "-1.5"},
+		{in: int64(-3), want: "-3"},
+		{in: nil, want: ""},
+	}
+	for _, tt := range tests {
+		if got := formatCell(tt.in); got != tt.want {
+			t.Errorf("formatCell(%v) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestWriterEscapesFormulas(t *testing.T) {
+	t.Run(FormatCSV, func(t *testing.T) {
+		var buf bytes.Buffer
+		w, err := NewWriter(FormatCSV, &buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := w.WriteRow("=1+1", -2.5, "ok"); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if want := "\xef\xbb\xbf'=1+1,-2.5,ok\n"; buf.String() != want {
+			t.Errorf("csv = %q, want %q", buf.String(), want)
+		}
+	})
+
+	t.Run(FormatXLSX, func(t *testing.T) {
+		var buf bytes.Buffer
+		w, err := NewWriter(FormatXLSX, &buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := w.WriteRow("=1+1", -2.5); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		f, err := excelize.OpenReader(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		if formula, _ := f.GetCellFormula(sheetName, "A1"); formula != "" {
+			t.Errorf("A1 formula = %q, want none", formula)
+		}
+		if v, _ := f.GetCellValue(sheetName, "A1"); v != "'=1+1" {
+			t.Errorf("A1 = %q, want '=1+1", v)
+		}
+		if v, _ := f.GetCellValue(sheetName, "B1"); v != "-2.5" {
+			t.Errorf("B1 = %q, want -2.5", v)
+		}
+	})
+}
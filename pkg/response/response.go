@@ -0,0 +1,64 @@
+// Package response 统一的 HTTP 响应格式
+//
+// 所有 JSON 接口都返回 {code, message, data, request_id}，code 为 0 表示成功，
+// 非 0 时取值见 pkg/errcode。
+package response
+
+import (
+	"ProtectedArea/pkg/errcode"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDKey gin.Context 中保存请求 ID 的键，由 RequestID 中间件写入
+const RequestIDKey = "request_id"
+
+// Body 统一响应结构
+type Body struct {
+	Code      int         `json:"code"`
+	Message   string      `json:"message"`
+	Data      interface{} `json:"data"`
+	RequestID string      `json:"request_id"`
+}
+
+// Success 返回 200 和数据
+func Success(c *gin.Context, data interface{}) {
+	write(c, http.StatusOK, errcode.OK, "success", data)
+}
+
+// Created 返回 201 和新创建的资源
+func Created(c *gin.Context, data interface{}) {
+	write(c, http.StatusCreated, errcode.OK, "success", data)
+}
+
+// Error 根据错误码返回对应的 HTTP 状态码；非 errcode 错误按内部错误处理，原始错误只写日志
+func Error(c *gin.Context, err error) {
+	e := errcode.From(err)
+	if e.HTTPStatus() >= http.StatusInternalServerError {
+		log.Printf("[%s] %s %s: %v", c.GetString(RequestIDKey), c.Request.Method, c.Request.URL.Path, err)
+	}
+	_ = c.Error(err)
+	write(c, e.HTTPStatus(), e.Code, e.Message, nil)
+}
+
+// InvalidParams 参数绑定/校验失败的快捷方法
+func InvalidParams(c *gin.Context, err error) {
+	Error(c, errcode.InvalidParams.WithMessage(err.Error()))
+}
+
+// write 写入响应，错误响应会中断后续的 handler
+func write(c *gin.Context, status, code int, message string, data interface{}) {
+	body := Body{
+		Code:      code,
+		Message:   message,
+		Data:      data,
+		RequestID: c.GetString(RequestIDKey),
+	}
+	if code != errcode.OK {
+		c.AbortWithStatusJSON(status, body)
+		return
+	}
+	c.JSON(status, body)
+}
@@ -0,0 +1,221 @@
+// Package thumbnail 按需缩放图片并缓存到磁盘
+//
+// 缓存文件名由源文件路径、修改时间、大小和缩放参数计算得到，源文件被替换后自动使用新的缓存；
+// 旧的缓存文件不会自动删除，需要时可以直接清空缓存目录。
+// 宽高和质量先经过 Snap 取整到少量标准值，每张图片的缓存文件数量有上限；
+// 解码前先检查像素数，并限制同时生成的缩略图个数，避免超大图片耗尽内存。
+package thumbnail
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/HugoSmits86/nativewebp"
+	"golang.org/x/image/draw"
+)
+
+// 支持的输出格式
+const (
+	FormatJPEG = "jpeg"
+	FormatPNG  = "png"
+	FormatWebP = "webp"
+)
+
+// 参数范围
+const (
+	MaxSize        = 4096     // 宽/高的最大值 (像素)
+	DefaultQuality = 80       // JPEG 默认质量
+	MaxPixels      = 50 << 20 // 允许解码的源图最大像素数 (约 5000 万)，解码后约占 4 字节/像素
+)
+
+// maxRenders 同时生成缩略图的最大个数，超出的请求排队等待
+const maxRenders = 4
+
+// snapSizes 允许的宽高，请求的尺寸向上取整到其中最近的一个
+var snapSizes = []int{64, 128, 256, 320, 480, 640, 800, 1024, 1280, 1600, 1920, 2560, 3200, MaxSize}
+
+var (
+	// ErrDecode 源文件不是可以解析的图片
+	ErrDecode = errors.New("无法解析图片")
+	// ErrTooLarge 源图像素数超过 MaxPixels
+	ErrTooLarge = errors.New("图片像素过多")
+)
+
+// Options 缩放参数
+// Width/Height 为 0 表示不限制该方向，只指定一个时按比例缩放；图片不会被放大
+// Quality 只对 JPEG 有效 (WebP 使用无损编码)
+type Options struct {
+	Width   int
+	Height  int
+	Quality int
+	Format  string
+}
+
+// IsSupported 判断是否为支持的输出格式
+func IsSupported(format string) bool {
+	return format == FormatJPEG || format == FormatPNG || format == FormatWebP
+}
+
+// Ext 返回输出格式对应的文件后缀
+func Ext(format string) string {
+	switch format {
+	case FormatPNG:
+		return ".png"
+	case FormatWebP:
+		return ".webp"
+	default:
+		return ".jpg"
+	}
+}
+
+// Snap 把宽高向上取整到标准尺寸、JPEG 质量取整到 10 的倍数，减少同一张图片的缓存版本
+// 缓存键和 ETag 都应使用 Snap 之后的参数
+func Snap(opts Options) Options {
+	opts.Width, opts.Height = snapSize(opts.Width), snapSize(opts.Height)
+	if opts.Quality > 0 {
+		opts.Quality = min(100, max(10, (opts.Quality+5)/10*10))
+	}
+	return opts
+}
+
+// snapSize 0 (不限制) 保持不变
+func snapSize(n int) int {
+	if n <= 0 {
+		return 0
+	}
+	for _, size := range snapSizes {
+		if n <= size {
+			return size
+		}
+	}
+	return MaxSize
+}
+
+// Key 计算源文件 + 缩放参数对应的缓存键，也可以直接作为 HTTP ETag
+func Key(src string, modTime time.Time, size int64, opts Options) string {
+	h := sha1.New()
+	fmt.Fprintf(h, "%s|%d|%d|%dx%d|q%d|%s", src, modTime.UnixNano(), size, opts.Width, opts.Height, opts.Quality, opts.Format)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Cache 磁盘缩略图缓存
+type Cache struct {
+	dir     string
+	renders chan struct{} // 生成缩略图的信号量
+}
+
+// NewCache dir 为缓存目录，不存在时在第一次写入时创建
+func NewCache(dir string) *Cache {
+	return &Cache{dir: dir, renders: make(chan struct{}, maxRenders)}
+}
+
+// Get 返回 src 按 opts 缩放后的缓存文件路径，缓存不存在时生成
+// 先写临时文件再重命名，并发请求同一缩略图时最多重复生成，不会读到写了一半的文件
+func (c *Cache) Get(src string, info os.FileInfo, opts Options) (string, error) {
+	key := Key(src, info.ModTime(), info.Size(), opts)
+	// 按前两位分子目录，避免单个目录下文件过多
+	path := filepath.Join(c.dir, key[:2], key+Ext(opts.Format))
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+
+	// 限制并发生成；排队期间其它请求可能已经生成了同一张缩略图
+	c.renders <- struct{}{}
+	defer func() { <-c.renders }()
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), key+".*.tmp")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name()) // 重命名成功后删除会失败，可以忽略
+
+	if err := Render(src, opts, tmp); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// Render 读取 src，按 opts 缩放后编码写入 w；源图像素数超过 MaxPixels 时返回 ErrTooLarge
+func Render(src string, opts Options, w io.Writer) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	// 1. 只读文件头检查尺寸，再完整解码
+	cfg, _, err := image.DecodeConfig(f)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDecode, err)
+	}
+	if int64(cfg.Width)*int64(cfg.Height) > MaxPixels {
+		return fmt.Errorf("%w: %dx%d", ErrTooLarge, cfg.Width, cfg.Height)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDecode, err)
+	}
+
+	// 2. 计算目标尺寸 (保持宽高比，不放大)
+	b := img.Bounds()
+	width, height := fitSize(b.Dx(), b.Dy(), opts.Width, opts.Height)
+
+	// 3. 缩放；JPEG 不支持透明，先铺白色背景
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	if opts.Format == FormatJPEG {
+		draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	}
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, b, draw.Over, nil)
+
+	// 4. 编码
+	switch opts.Format {
+	case FormatPNG:
+		return png.Encode(w, dst)
+	case FormatWebP:
+		return nativewebp.Encode(w, dst, nil)
+	default:
+		quality := opts.Quality
+		if quality <= 0 {
+			quality = DefaultQuality
+		}
+		return jpeg.Encode(w, dst, &jpeg.Options{Quality: quality})
+	}
+}
+
+// fitSize 在 maxW × maxH 范围内按比例缩放 (0 表示不限制)，结果不超过原图且至少为 1 像素
+func fitSize(w, h, maxW, maxH int) (int, int) {
+	scale := 1.0
+	if maxW > 0 && maxW < w {
+		scale = float64(maxW) / float64(w)
+	}
+	if maxH > 0 && maxH < h {
+		scale = min(scale, float64(maxH)/float64(h))
+	}
+	return max(1, int(float64(w)*scale+0.5)), max(1, int(float64(h)*scale+0.5))
+}
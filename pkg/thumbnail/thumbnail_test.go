@@ -0,0 +1,175 @@
+package thumbnail
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSnap(t *testing.T) {
+	tests := []struct {
+		in, want Options
+	}{
+		{in: Options{}, want: Options{}},
+		{in: Options{Width: 1, Height: 64}, want: Options{Width: 64, Height: 64}},
+		{in: Options{Width: 300}, want: Options{Width: 320}},
+		{in: Options{Width: 1025, Height: -5}, want: Options{Width: 1280}},
+		{in: Options{Width: 99999}, want: Options{Width: MaxSize}},
+		{in: Options{Quality: 84, Format: FormatJPEG}, want: Options{Quality: 80, Format: FormatJPEG}},
+		{in: Options{Quality: 85}, want: Options{Quality: 90}},
+		{in: Options{Quality: 1}, want: Options{Quality: 10}},
+		{in: Options{Quality: 150}, want: Options{Quality: 100}},
+	}
+	for _, tt := range tests {
+		if got := Snap(tt.in); got != tt.want {
+			t.Errorf("Snap(%+v) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+
+	// 相近的参数得到相同的缓存键
+	mod := time.Unix(1700000000, 0)
+	a := Key("a.jpg", mod, 100, Snap(Options{Width: 500, Quality: 78}))
+	b := Key("a.jpg", mod, 100, Snap(Options{Width: 620, Quality: 82}))
+	if a != b {
+		t.Errorf("snapped keys differ: %s != %s", a, b)
+	}
+}
+
+func TestFitSize(t *testing.T) {
+	tests := []struct {
+		w, h, maxW, maxH int
+		wantW, wantH     int
+	}{
+		{w: 1000, h: 500, wantW: 1000, wantH: 500},
+		{w: 1000, h: 500, maxW: 200, wantW: 200, wantH: 100},
+		{w: 1000, h: 500, maxH: 100, wantW: 200, wantH: 100},
+		{w: 1000, h: 500, maxW: 400, maxH: 100, wantW: 200, wantH: 100},
+		// 不放大
+		{w: 100, h: 50, maxW: 400, maxH: 400, wantW: 100, wantH: 50},
+		// 至少 1 像素
+		{w: 10000, h: 1, maxW: 100, wantW: 100, wantH: 1},
+	}
+	for _, tt := range tests {
+		w, h := fitSize(tt.w, tt.h, tt.maxW, tt.maxH)
+		if w != tt.wantW || h != tt.wantH {
+			t.Errorf("fitSize(%d, %d, %d, %d) = %d, %d, want %d, %d", tt.w, tt.h, tt.maxW, tt.maxH, w, h, tt.wantW, tt.wantH)
+		}
+	}
+}
+
+// pngHeader 只包含 IHDR 的 PNG 文件头，DecodeConfig 可以读出尺寸，但无法完整解码
+func pngHeader(width, height uint32) []byte {
+	var ihdr bytes.Buffer
+	ihdr.WriteString("IHDR")
+	binary.Write(&ihdr, binary.BigEndian, width)
+	binary.Write(&ihdr, binary.BigEndian, height)
+	ihdr.Write([]byte{8, 6, 0, 0, 0}) // 8 位 RGBA
+
+	var buf bytes.Buffer
+	buf.WriteString("\x89PNG\r\n\x1a\n")
+	binary.Write(&buf, binary.BigEndian, uint32(ihdr.Len()-4))
+	buf.Write(ihdr.Bytes())
+	binary.Write(&buf, binary.BigEndian, crc32.ChecksumIEEE(ihdr.Bytes()))
+	return buf.Bytes()
+}
+
+func TestRender(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, data []byte) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	var small bytes.Buffer
+	if err := png.Encode(&small, image.NewRGBA(image.Rect(0, 0, 400, 200))); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		src     string
+		opts    Options
+		wantW   int
+		wantH   int
+		wantErr error
+	}{
+		{name: "缩放为 JPEG", src: write("small.png", small.Bytes()), opts: Options{Width: 100, Format: FormatJPEG}, wantW: 100, wantH: 50},
+		{name: "缩放为 PNG", src: write("small2.png", small.Bytes()), opts: Options{Height: 20, Format: FormatPNG}, wantW: 40, wantH: 20},
+		// 像素数超限时只读文件头就拒绝，不会尝试分配内存解码
+		{name: "像素过多", src: write("huge.png", pngHeader(10000, 10000)), opts: Options{Width: 100}, wantErr: ErrTooLarge},
+		{name: "刚好超过上限", src: write("limit.png", pngHeader(MaxPixels/1024+1, 1024)), opts: Options{Width: 100}, wantErr: ErrTooLarge},
+		{name: "不是图片", src: write("text.jpg", []byte("not an image")), opts: Options{Width: 100}, wantErr: ErrDecode},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			err := Render(tt.src, tt.opts, &out)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			cfg, format, err := image.DecodeConfig(&out)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if format != tt.opts.Format || cfg.Width != tt.wantW || cfg.Height != tt.wantH {
+				t.Errorf("output = %s %dx%d, want %s %dx%d", format, cfg.Width, cfg.Height, tt.opts.Format, tt.wantW, tt.wantH)
+			}
+		})
+	}
+}
+
+func TestCacheGet(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "a.png")
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 64, 64))); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(src, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewCache(filepath.Join(dir, "cache"))
+	opts := Options{Width: 32, Format: FormatPNG}
+	first, err := c.Get(src, info, opts)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	second, err := c.Get(src, info, opts)
+	if err != nil || second != first {
+		t.Errorf("second Get = %q, %v, want cached %q", second, err, first)
+	}
+
+	// 生成失败时不留下缓存文件
+	bad := filepath.Join(dir, "huge.png")
+	if err := os.WriteFile(bad, pngHeader(20000, 20000), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	badInfo, _ := os.Stat(bad)
+	if _, err := c.Get(bad, badInfo, opts); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("Get huge image err = %v, want ErrTooLarge", err)
+	}
+	matches, _ := filepath.Glob(filepath.Join(dir, "cache", "*", "*"))
+	if len(matches) != 1 {
+		t.Errorf("cache files = %v, want only the first thumbnail", matches)
+	}
+}